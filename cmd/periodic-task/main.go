@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"os"
 	periodichttp "periodic-task/internal/http"
+	"periodic-task/pkg/holiday"
 	periodicsrv "periodic-task/pkg/periodic-task"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -16,6 +18,8 @@ const (
 	defaultRWTimeout     = "15"
 	defaultIdleTimeout   = "15"
 	defaultServerTimeout = "15"
+	defaultMaxCount      = "100000"
+	defaultMaxRange      = "876000h"
 )
 
 // Run sets up our application
@@ -30,8 +34,34 @@ func Run() error {
 
 	log.Info("setting up periodic task")
 
+	// Load the holiday calendars, if a directory is given
+	var calendars holiday.Store
+	if dir := envString("CALENDAR_DIR", ""); dir != "" {
+		var err error
+		if calendars, err = holiday.LoadDir(dir); err != nil {
+			log.Error("failed to load the holiday calendars from " + dir)
+			return err
+		}
+		log.Infof("loaded %d holiday calendars from %s", len(calendars), dir)
+	}
+
+	// Get the limits of the lists of timestamps from the enviroment variables
+	maxCount, err := strconv.ParseInt(envString("MAX_COUNT", defaultMaxCount), 10, 0)
+	if err != nil {
+		log.Error("failed to parse MAX_COUNT")
+		return err
+	}
+	maxRange, err := parseMaxRange(envString("MAX_RANGE", defaultMaxRange))
+	if err != nil {
+		log.Error("failed to parse MAX_RANGE")
+		return err
+	}
+
 	// Setup period service
-	ps := periodicsrv.NewService(log)
+	ps := periodicsrv.NewService(log, calendars, periodicsrv.Limits{
+		MaxCount: int(maxCount),
+		MaxRange: maxRange,
+	})
 
 	srv := periodichttp.New(ps, log)
 
@@ -66,6 +96,10 @@ func Run() error {
 		return err
 	}
 
+	// The deadline of the requests is before the write timeout, so that
+	// their 504 is written
+	srv.Timeout = periodichttp.RequestTimeout(time.Duration(timeout)*time.Second, rwt)
+
 	if err := srv.Serve(server, timeout); err != nil {
 		log.Error("failed to gracefully serve periodic task")
 		return err
@@ -88,3 +122,26 @@ func envString(env, fallback string) string {
 	}
 	return e
 }
+
+// parseMaxRange parses the maximum ranges of the lists and the counts, a comma
+// separated list of a duration by kind of period (1h=8760h or cron=8760h, see
+// period.Kind), and of the duration of the other kinds (876000h).
+func parseMaxRange(s string) (map[string]time.Duration, error) {
+	ranges := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		kind, sd := "", part
+		if i := strings.Index(part, "="); i >= 0 {
+			kind, sd = part[:i], part[i+1:]
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(sd))
+		if err != nil {
+			return nil, err
+		}
+		ranges[strings.TrimSpace(kind)] = d
+	}
+	return ranges, nil
+}
@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/signal"
 	periodictask "periodic-task/pkg/periodic-task"
-	"strconv"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -20,6 +19,10 @@ type Server struct {
 
 	Logger *zap.SugaredLogger
 
+	// Timeout is the deadline of the requests, which stops the generation of
+	// their timestamps, none when it is zero (see RequestTimeout).
+	Timeout time.Duration
+
 	router chi.Router
 }
 
@@ -43,7 +46,7 @@ func New(ps periodictask.Service, logger *zap.SugaredLogger) *Server {
 			S: s.Period,
 			L: s.Logger,
 		}
-		r.Mount("/ptlist", ph.Router())
+		r.Mount("/", ph.Router())
 	})
 
 	r.Get("/alive", s.aliveCheck)
@@ -64,16 +67,35 @@ func (s *Server) jsonMiddleware(h http.Handler) http.Handler {
 	})
 }
 
+// timeoutMiddleware sets the deadline of the Timeout on the context of a
+// request, which stops the generation of its timestamps. There is none when
+// the Timeout is zero, rather than a deadline that already passed.
 func (s *Server) timeoutMiddleware(h http.Handler) http.Handler {
-	timeout := os.Getenv("SERVER_TIMEOUT")
-	serverTimeout, _ := strconv.ParseInt(timeout, 10, 0)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(serverTimeout)*time.Second)
+		if s.Timeout <= 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.Timeout)
 		defer cancel()
 		h.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RequestTimeout returns the deadline of the requests, the timeout, but a
+// tenth of the write timeout before it, so that the error response of a
+// request past its deadline is written before the server closes the
+// connection. A zero timeout or write timeout is none.
+func RequestTimeout(timeout, writeTimeout time.Duration) time.Duration {
+	if writeTimeout <= 0 {
+		return timeout
+	}
+	if max := writeTimeout - writeTimeout/10; timeout <= 0 || timeout > max {
+		return max
+	}
+	return timeout
+}
+
 // loggingMiddleware is a handy middleware function that logs out incoming requests
 func (s *Server) loggingMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,266 @@
+package holiday
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dateFormat is the format of the holiday dates in JSON and YAML files.
+const dateFormat = "2006-01-02"
+
+// date is a calendar day.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// Calendar is a holiday calendar, which tells the non-working days.
+type Calendar struct {
+	Name string
+	// Weekend holds the weekly non-working days, Saturday and Sunday by default.
+	Weekend  []time.Weekday
+	holidays map[date]string
+}
+
+// NewCalendar returns an empty calendar with a Saturday and Sunday weekend.
+func NewCalendar(name string) *Calendar {
+	return &Calendar{
+		Name:     name,
+		Weekend:  []time.Weekday{time.Saturday, time.Sunday},
+		holidays: make(map[date]string),
+	}
+}
+
+// Add adds a holiday on the day of t.
+func (c *Calendar) Add(t time.Time, name string) {
+	c.holidays[dateOf(t)] = name
+}
+
+// IsHoliday reports whether the day of t, in t's location, is a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	_, ok := c.holidays[dateOf(t)]
+	return ok
+}
+
+// IsBusinessDay reports whether the day of t, in t's location, is neither
+// a weekend day nor a holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	for _, wd := range c.Weekend {
+		if t.Weekday() == wd {
+			return false
+		}
+	}
+	return !c.IsHoliday(t)
+}
+
+// Store holds the holiday calendars by name.
+type Store map[string]*Calendar
+
+// LoadDir loads all the calendars (.ics, .json, .yaml, .yml) of a directory.
+// A calendar is named after its file (GR.json is GR), unless the file
+// gives a name.
+func LoadDir(dir string) (Store, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	store := make(Store)
+	for _, e := range entries {
+		if e.IsDir() || !supported(e.Name()) {
+			continue
+		}
+		c, err := LoadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := store[c.Name]; ok {
+			return nil, fmt.Errorf("calendar %s is defined more than once", c.Name)
+		}
+		store[c.Name] = c
+	}
+	return store, nil
+}
+
+// supported reports whether the file has a supported calendar extension.
+func supported(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ics", ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadFile loads a calendar from an iCalendar, JSON or YAML file.
+func LoadFile(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	c := NewCalendar(strings.TrimSuffix(base, ext))
+
+	switch strings.ToLower(ext) {
+	case ".ics":
+		err = c.readICS(f)
+	case ".json":
+		err = c.readDocument(f, json.Unmarshal)
+	case ".yaml", ".yml":
+		err = c.readDocument(f, yaml.Unmarshal)
+	default:
+		err = fmt.Errorf("unsupported calendar format %s", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// document is the layout of a JSON or YAML calendar:
+//
+//	name: GR
+//	weekend: [saturday, sunday]
+//	holidays:
+//	  - date: 2021-03-25
+//	    name: Independence Day
+type document struct {
+	Name     string   `json:"name" yaml:"name"`
+	Weekend  []string `json:"weekend" yaml:"weekend"`
+	Holidays []struct {
+		Date string `json:"date" yaml:"date"`
+		Name string `json:"name" yaml:"name"`
+	} `json:"holidays" yaml:"holidays"`
+}
+
+func (c *Calendar) readDocument(r io.Reader, unmarshal func([]byte, interface{}) error) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var doc document
+	if err := unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	if doc.Name != "" {
+		c.Name = doc.Name
+	}
+	if doc.Weekend != nil {
+		c.Weekend = nil
+		for _, s := range doc.Weekend {
+			wd, err := parseWeekday(s)
+			if err != nil {
+				return err
+			}
+			c.Weekend = append(c.Weekend, wd)
+		}
+	}
+	for _, h := range doc.Holidays {
+		t, err := time.Parse(dateFormat, h.Date)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid date (%s)", h.Date, dateFormat)
+		}
+		c.Add(t, h.Name)
+	}
+	return nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(s, wd.String()) || strings.EqualFold(s, wd.String()[:3]) {
+			return wd, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a valid weekday", s)
+}
+
+// readICS reads the VEVENTs of an iCalendar file as holidays. An event lasts
+// from its DTSTART until the day before its DTEND, or only on its DTSTART.
+func (c *Calendar) readICS(r io.Reader) error {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Unfold the long lines, which continue with a space or a tab
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var inEvent bool
+	var start, end time.Time
+	var summary string
+	for _, line := range lines {
+		name, value := splitICSLine(line)
+		switch {
+		case name == "X-WR-CALNAME" && !inEvent:
+			c.Name = value
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+		case name == "END" && value == "VEVENT":
+			if start.IsZero() {
+				return fmt.Errorf("an event has no DTSTART")
+			}
+			c.Add(start, summary)
+			for d := start.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.Add(d, summary)
+			}
+			inEvent = false
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			// Only the date of the DATE or DATE-TIME value matters
+			if len(value) < len("20060102") {
+				return fmt.Errorf("%q is not a valid date", value)
+			}
+			t, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return fmt.Errorf("%q is not a valid date", value)
+			}
+			if name == "DTSTART" {
+				start = t
+			} else {
+				end = t
+			}
+		case inEvent && name == "SUMMARY":
+			summary = value
+		}
+	}
+	return nil
+}
+
+// splitICSLine splits a content line into its name, without parameters,
+// and its value.
+func splitICSLine(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", ""
+	}
+	name := line[:i]
+	if j := strings.Index(name, ";"); j >= 0 {
+		name = name[:j]
+	}
+	return strings.ToUpper(name), line[i+1:]
+}
@@ -0,0 +1,97 @@
+package holiday
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse(dateFormat, s)
+	return t
+}
+
+func TestHoliday_LoadDir(t *testing.T) {
+	store, err := LoadDir("testdata")
+	assert.NoError(t, err)
+	assert.Len(t, store, 3, "Expected the calendars of the supported files")
+
+	t.Run("JSON", func(t *testing.T) {
+		gr := store["GR"]
+		if !assert.NotNil(t, gr, "Expected the calendar named after the file") {
+			return
+		}
+		assert.True(t, gr.IsHoliday(day("2021-03-25")))
+		assert.False(t, gr.IsHoliday(day("2021-03-26")))
+		assert.False(t, gr.IsBusinessDay(day("2021-01-06")), "Holiday")
+		assert.False(t, gr.IsBusinessDay(day("2021-01-09")), "Saturday")
+		assert.True(t, gr.IsBusinessDay(day("2021-01-07")))
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		ae := store["AE"]
+		if !assert.NotNil(t, ae, "Expected the calendar named in the file") {
+			return
+		}
+		assert.True(t, ae.IsHoliday(day("2021-12-02")))
+		assert.True(t, ae.IsHoliday(day("2021-12-03")))
+		assert.False(t, ae.IsBusinessDay(day("2021-12-04")), "Saturday")
+		assert.True(t, ae.IsBusinessDay(day("2021-12-06")))
+	})
+
+	t.Run("ICS", func(t *testing.T) {
+		de := store["DE"]
+		if !assert.NotNil(t, de, "Expected the calendar named by X-WR-CALNAME") {
+			return
+		}
+		// DTEND is exclusive
+		assert.True(t, de.IsHoliday(day("2021-12-24")))
+		assert.True(t, de.IsHoliday(day("2021-12-25")))
+		assert.True(t, de.IsHoliday(day("2021-12-26")))
+		assert.False(t, de.IsHoliday(day("2021-12-27")))
+		assert.Equal(t, "Christmas holidays", de.holidays[dateOf(day("2021-12-25"))])
+		// A DATE-TIME is a holiday on its date
+		assert.True(t, de.IsHoliday(day("2021-10-03")))
+	})
+}
+
+func TestHoliday_LoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"date.json":    `{"holidays": [{"date": "25/03/2021"}]}`,
+		"weekend.yaml": "weekend: [someday]\n",
+		"syntax.json":  `{"holidays": `,
+		"event.ics":    "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No date\nEND:VEVENT\nEND:VCALENDAR\n",
+		"calendar.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadFile(path)
+		assert.Error(t, err, name)
+	}
+
+	_, err := LoadDir(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestHoliday_Weekend(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "IL.json")
+	content := `{"weekend": ["fri", "Saturday"], "holidays": []}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	il, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, il.IsBusinessDay(day("2021-12-03")), "Friday")
+	assert.False(t, il.IsBusinessDay(day("2021-12-04")), "Saturday")
+	assert.True(t, il.IsBusinessDay(day("2021-12-05")), "Sunday")
+}
@@ -0,0 +1,172 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar tells the non-working days of a holiday calendar.
+type Calendar interface {
+	// IsBusinessDay reports whether the day of t, in t's location, is a
+	// working day.
+	IsBusinessDay(t time.Time) bool
+}
+
+// Convention is a business day convention, which moves the timestamps that
+// fall on non-working days.
+type Convention int
+
+// Constants for all supported business day conventions
+const (
+	// NoAdjustment keeps the timestamps as they are.
+	NoAdjustment Convention = iota
+	// Skip drops the timestamps on non-working days.
+	Skip
+	// Following moves the timestamps to the next business day.
+	Following
+	// ModifiedFollowing moves the timestamps to the next business day,
+	// unless it is in the next month, where they move to the previous one.
+	ModifiedFollowing
+	// Preceding moves the timestamps to the previous business day.
+	Preceding
+	// ModifiedPreceding moves the timestamps to the previous business day,
+	// unless it is in the previous month, where they move to the next one.
+	ModifiedPreceding
+)
+
+var conventions = map[string]Convention{
+	"":                  NoAdjustment,
+	"none":              NoAdjustment,
+	"skip":              Skip,
+	"following":         Following,
+	"modifiedfollowing": ModifiedFollowing,
+	"preceding":         Preceding,
+	"modifiedpreceding": ModifiedPreceding,
+}
+
+// maxAdjustment bounds how far a timestamp moves to a business day.
+const maxAdjustment = 31
+
+// ParseConvention parses a business day convention such as following or
+// modified-following.
+func ParseConvention(s string) (Convention, error) {
+	key := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(s))
+	c, ok := conventions[key]
+	if !ok {
+		return NoAdjustment, fmt.Errorf("%q is not a supported business day convention "+
+			"(none, skip, following, modified-following, preceding, modified-preceding)", s)
+	}
+	return c, nil
+}
+
+// String returns the name of the convention, in the form ParseConvention
+// parses.
+func (c Convention) String() string {
+	switch c {
+	case Skip:
+		return "skip"
+	case Following:
+		return "following"
+	case ModifiedFollowing:
+		return "modified-following"
+	case Preceding:
+		return "preceding"
+	case ModifiedPreceding:
+		return "modified-preceding"
+	default:
+		return "none"
+	}
+}
+
+// weekends is the calendar used without a holiday calendar, where only
+// Saturday and Sunday are non-working days.
+type weekends struct{}
+
+func (weekends) IsBusinessDay(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// Adjusted Period, which moves the timestamps of a period that fall on the
+// non-working days of a calendar following a business day convention.
+type AdjustedPeriod struct {
+	Period Period
+	// Calendar tells the non-working days, Saturday and Sunday when nil.
+	Calendar   Calendar
+	Convention Convention
+	// DST is the daylight saving time policy of the moved timestamps.
+	DST DST
+}
+
+func (ap AdjustedPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(ap.Iter(context.Background(), t1, t2, tz))
+}
+
+func (ap AdjustedPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	cal := ap.Calendar
+	if cal == nil {
+		cal = weekends{}
+	}
+
+	// Widen the range, since the timestamps around it may move into it
+	margin := maxAdjustment * 24 * time.Hour
+	it := ap.Period.Iter(ctx, t1.Add(-margin), t2.Add(margin), tz)
+
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		if !it.Next() {
+			return nil, time.Time{}, false, it.Err()
+		}
+		t := it.Time().In(tz)
+		// The next timestamps move at most back to the margin before t
+		floor := t.Add(-margin - dstMargin)
+		if ap.Convention == NoAdjustment || cal.IsBusinessDay(t) {
+			return []time.Time{t}, floor, true, nil
+		}
+
+		// Move the wall clock, and drop the timestamps without a business
+		// day. The ones that move onto the same timestamp (e.g. Saturday
+		// and Sunday to Monday) are merged.
+		c, ok := ap.adjust(toCivil(t), cal)
+		if !ok {
+			return nil, floor, true, nil
+		}
+		return ap.DST.resolve(c, tz), floor, true, nil
+	})
+}
+
+// adjust moves the wall clock c of a non-working day to a business day
+// keeping its time of the day. It reports false when c is dropped.
+func (ap AdjustedPeriod) adjust(c time.Time, cal Calendar) (time.Time, bool) {
+	switch ap.Convention {
+	case Following:
+		return moveToBusinessDay(c, cal, 1)
+	case Preceding:
+		return moveToBusinessDay(c, cal, -1)
+	case ModifiedFollowing:
+		if a, ok := moveToBusinessDay(c, cal, 1); ok && a.Month() == c.Month() {
+			return a, true
+		}
+		return moveToBusinessDay(c, cal, -1)
+	case ModifiedPreceding:
+		if a, ok := moveToBusinessDay(c, cal, -1); ok && a.Month() == c.Month() {
+			return a, true
+		}
+		return moveToBusinessDay(c, cal, 1)
+	default:
+		return c, false
+	}
+}
+
+// moveToBusinessDay returns the closest business day after (step 1) or
+// before (step -1) the wall clock c, keeping its time of the day. The
+// calendar is asked about the civil date of c.
+func moveToBusinessDay(c time.Time, cal Calendar, step int) (time.Time, bool) {
+	for i := 1; i <= maxAdjustment; i++ {
+		a := c.AddDate(0, 0, i*step)
+		if cal.IsBusinessDay(a) {
+			return a, true
+		}
+	}
+	return c, false
+}
@@ -0,0 +1,102 @@
+package period
+
+import (
+	"fmt"
+	"time"
+)
+
+// Anchor is a time that a period matches, which fixes its phase, e.g. every
+// 90 minutes starting from 2020-01-01T00:15. The timestamps of an anchored
+// period are the same whatever the requested range.
+type Anchor struct {
+	// Time is the anchor, an instant, or a wall clock in the requested
+	// timezone when Floating.
+	Time     time.Time
+	Floating bool
+}
+
+// anchorLayouts are the supported forms of an anchor, and whether they are
+// floating.
+var anchorLayouts = []struct {
+	layout   string
+	floating bool
+}{
+	{SUPPORTEDFORMAT, false},
+	{time.RFC3339Nano, false},
+	{"20060102T150405", true},
+	{"2006-01-02T15:04:05", true},
+	{"2006-01-02T15:04", true},
+	{"2006-01-02", true},
+}
+
+// ParseAnchor parses an anchor, which is either an instant in the
+// SUPPORTEDFORMAT or in RFC 3339 (20200101T001500Z, 2020-01-01T00:15:00+02:00),
+// or a wall clock in the requested timezone (20200101T001500,
+// 2020-01-01T00:15, 2020-01-01). An empty string is no anchor. The anchor is
+// after the zero time and before the year 10000, so that its distance from
+// any requested time is bounded.
+func ParseAnchor(s string) (Anchor, error) {
+	if s == "" {
+		return Anchor{}, nil
+	}
+	for _, l := range anchorLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			if !t.After(time.Time{}) || t.UTC().Year() > 9999 {
+				return Anchor{}, fmt.Errorf("%q is out of the supported years 0001 to 9999", s)
+			}
+			return Anchor{Time: t, Floating: l.floating}, nil
+		}
+	}
+	return Anchor{}, fmt.Errorf("%q is not a valid anchor (e.g. 20200101T001500Z, "+
+		"2020-01-01T00:15:00+02:00 or 2020-01-01T00:15 in the requested timezone)", s)
+}
+
+// IsZero reports whether there is no anchor.
+func (a Anchor) IsZero() bool {
+	return a.Time.IsZero()
+}
+
+// String returns the anchor in the SUPPORTEDFORMAT, or without its Z when it
+// is floating. It is empty when there is no anchor.
+func (a Anchor) String() string {
+	switch {
+	case a.IsZero():
+		return ""
+	case a.Floating:
+		return a.Time.Format("20060102T150405")
+	default:
+		return a.Time.UTC().Format(SUPPORTEDFORMAT)
+	}
+}
+
+// civil returns the wall clock of the anchor in loc, a civil time in UTC.
+func (a Anchor) civil(loc *time.Location) time.Time {
+	if a.Floating {
+		return toCivil(a.Time)
+	}
+	return toCivil(a.Time.In(loc))
+}
+
+// instant returns the instant of the anchor in loc. A floating anchor that
+// does not exist is shifted forward, and an ambiguous one is the first.
+func (a Anchor) instant(loc *time.Location) time.Time {
+	if a.Floating {
+		t, _, _ := locate(toCivil(a.Time), loc)
+		return t.In(loc)
+	}
+	return a.Time.In(loc)
+}
+
+// anchorable is implemented by the periods that support an anchor.
+type anchorable interface {
+	withAnchor(a Anchor) (Period, error)
+}
+
+// floorDiv returns a / b rounded down, for a positive b.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,312 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Anchor(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		anchor   string
+		at       string
+		t1       string
+		t2       string
+		expected []string
+	}{
+		{
+			name:   "Every 90 minutes from 00:15 local",
+			period: "90m",
+			anchor: "2020-01-01T00:15",
+			t1:     "20210714T200000Z",
+			t2:     "20210715T000000Z",
+			// 2020-01-01T00:15 local is 2019-12-31T22:15Z
+			expected: []string{"20210714T204500Z", "20210714T221500Z", "20210714T234500Z"},
+		},
+		{
+			name:     "Every 90 minutes from an instant",
+			period:   "90m",
+			anchor:   "20191231T221500Z",
+			t1:       "20210714T200000Z",
+			t2:       "20210715T000000Z",
+			expected: []string{"20210714T204500Z", "20210714T221500Z", "20210714T234500Z"},
+		},
+		{
+			name:   "Every 2 days on the wall clock",
+			period: "2d",
+			anchor: "2021-07-01T09:30",
+			t1:     "20210701T000000Z",
+			t2:     "20210708T000000Z",
+			expected: []string{
+				"20210701T063000Z",
+				"20210703T063000Z",
+				"20210705T063000Z",
+				"20210707T063000Z",
+			},
+		},
+		{
+			name:     "Every 2 days from another range",
+			period:   "2d",
+			anchor:   "2021-07-01T09:30",
+			t1:       "20210702T000000Z",
+			t2:       "20210706T000000Z",
+			expected: []string{"20210703T063000Z", "20210705T063000Z"},
+		},
+		{
+			name:     "Every 2 days before the anchor",
+			period:   "2d",
+			anchor:   "2021-07-01T09:30",
+			t1:       "20210626T000000Z",
+			t2:       "20210701T000000Z",
+			expected: []string{"20210627T063000Z", "20210629T063000Z"},
+		},
+		{
+			name:   "Every month from the 31st",
+			period: ONEMONTH,
+			anchor: "2021-01-31",
+			at:     "start",
+			t1:     "20210201T000000Z",
+			t2:     "20210601T000000Z",
+			expected: []string{
+				"20210227T220000Z",
+				"20210330T210000Z",
+				"20210429T210000Z",
+				"20210530T210000Z",
+			},
+		},
+		{
+			name:     "Every week from Wednesday",
+			period:   ONEWEEK,
+			anchor:   "2021-07-07",
+			at:       "start",
+			t1:       "20210710T000000Z",
+			t2:       "20210725T000000Z",
+			expected: []string{"20210713T210000Z", "20210720T210000Z"},
+		},
+		{
+			name:     "ISO 8601 duration",
+			period:   "P1DT12H",
+			anchor:   "20210701T000000Z",
+			t1:       "20210702T000000Z",
+			t2:       "20210706T000000Z",
+			expected: []string{"20210702T120000Z", "20210704T000000Z", "20210705T120000Z"},
+		},
+		{
+			name:     "ISO 8601 duration before the anchor",
+			period:   "PT8H",
+			anchor:   "20210701T010000Z",
+			t1:       "20210630T000000Z",
+			t2:       "20210701T000000Z",
+			expected: []string{"20210630T010000Z", "20210630T090000Z", "20210630T170000Z"},
+		},
+		{
+			name:     "Recurrence rule without a DTSTART",
+			period:   "FREQ=DAILY;INTERVAL=3",
+			anchor:   "2021-07-01T09:00",
+			t1:       "20210705T000000Z",
+			t2:       "20210712T000000Z",
+			expected: []string{"20210707T060000Z", "20210710T060000Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+			anchor, err := ParseAnchor(tt.anchor)
+			assert.NoError(t, err)
+			at, err := ParseInvocation(tt.at)
+			assert.NoError(t, err)
+
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at, Anchor: anchor})
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_AnchorIsStable(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	anchor, _ := ParseAnchor("2020-01-01T00:15")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210301T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210501T000000Z")
+
+	// The timestamps of any window are the ones of the whole range
+	for _, s := range []string{"90m", "7h", "3d", "2w", "5mo", "PT90M", "P2DT3H",
+		"FREQ=HOURLY;INTERVAL=5"} {
+		t.Run(s, func(t *testing.T) {
+			p, err := ParsePeriodWithOptions(s, Options{Anchor: anchor})
+			assert.NoError(t, err)
+
+			all := MatchingTimes(p, t1.AddDate(-1, 0, 0), t2.Add(100*time.Hour), tz)
+			for w1 := t1; w1.Before(t2); w1 = w1.Add(37 * time.Hour) {
+				w2 := w1.Add(100 * time.Hour)
+
+				var expected []time.Time
+				for _, ts := range all {
+					if !ts.Before(w1) && ts.Before(w2) {
+						expected = append(expected, ts)
+					}
+				}
+				assert.Equal(t, expected, MatchingTimes(p, w1, w2, tz), "%s", w1)
+
+				n, err := Count(context.Background(), p, w1, w2, tz)
+				assert.NoError(t, err)
+				assert.Equal(t, len(expected), n, "%s", w1)
+			}
+		})
+	}
+}
+
+func TestPeriod_AnchorFar(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	// The anchors are more than the 292 years of a time.Duration away from
+	// the range, before or after it
+	tests := []struct {
+		anchor, t1 string
+	}{
+		{"20200101T000000Z", "25000101T000000Z"},
+		{"00010102T000000Z", "99990101T000000Z"},
+		{"99991231T000000Z", "20210101T000000Z"},
+	}
+	periods := map[string]int{
+		"1m": 60, "1h": 1, "15m": 4, "PT1M": 60, "PT15M": 4, "PT7.5S": 480,
+		"90m": -1, "7h": -1, "3d": -1, "5mo": -1, "PT90M": -1, "P2DT3H": -1, "P1M": -1,
+	}
+	for _, tt := range tests {
+		anchor, _ := ParseAnchor(tt.anchor)
+		t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+		for s, expected := range periods {
+			t.Run(tt.anchor+" "+s, func(t *testing.T) {
+				p, err := ParsePeriodWithOptions(s, Options{Anchor: anchor})
+				assert.NoError(t, err)
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+
+				// A long period has at most a timestamp in a year
+				t0, t2 := t1.Add(-24*time.Hour), t1.Add(time.Hour)
+				if expected < 0 {
+					t0, t2 = t1.AddDate(-1, 0, 0), t1.AddDate(1, 0, 0)
+				}
+				list, err := Collect(p.Iter(ctx, t1, t2, tz))
+				assert.NoError(t, err)
+				n, err := Count(ctx, p, t1, t2, tz)
+				assert.NoError(t, err)
+				assert.Equal(t, len(list), n)
+				if expected >= 0 {
+					assert.Len(t, list, expected)
+				}
+
+				// The timestamps are the ones of a longer range
+				all, err := Collect(p.Iter(ctx, t0, t2, tz))
+				assert.NoError(t, err)
+				assert.Equal(t, all[len(all)-len(list):], list)
+			})
+		}
+	}
+}
+
+func TestPeriod_AnchorBeforeClock(t *testing.T) {
+	// The time of the day is before the one of the anchor, so the point is
+	// before the start of its period, in zones far ahead of UTC
+	tests := []struct {
+		tz, period, anchor, at string
+	}{
+		{"Pacific/Kiritimati", "1d", "2020-01-01T23:00", "at 00:30"},
+		{"Pacific/Apia", "1d", "2020-01-01T23:00", "minute 5"},
+		{"Pacific/Apia", "1w", "2020-01-01T23:00", "day 1 at 00:05"},
+		{"Pacific/Apia", "1h", "2020-01-01T23:30", "minute 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tz+" "+tt.period, func(t *testing.T) {
+			tz, _ := time.LoadLocation(tt.tz)
+			anchor, _ := ParseAnchor(tt.anchor)
+			at, _ := ParseInvocation(tt.at)
+			p, err := ParsePeriodWithOptions(tt.period, Options{Anchor: anchor, At: at})
+			assert.NoError(t, err)
+
+			t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+			t2 := t1.AddDate(0, 0, 15)
+			all := MatchingTimes(p, t1, t2, tz)
+			n, err := Count(context.Background(), p, t1, t2, tz)
+			assert.NoError(t, err)
+			assert.Len(t, all, n)
+
+			// Every timestamp is in a narrow range around it, and matches
+			for _, ts := range all {
+				assert.Equal(t, []time.Time{ts}, MatchingTimes(p, ts.Add(-30*time.Minute),
+					ts.Add(30*time.Minute), tz), ts)
+				ok, err := Matches(context.Background(), p, ts, tz)
+				assert.NoError(t, err)
+				assert.True(t, ok, ts)
+			}
+		})
+	}
+
+	// 2021-01-02T00:30 in Kiritimati, at UTC+14
+	tz, _ := time.LoadLocation("Pacific/Kiritimati")
+	anchor, _ := ParseAnchor("2020-01-01T23:00")
+	at, _ := ParseInvocation("at 00:30")
+	p, err := ParsePeriodWithOptions("1d", Options{Anchor: anchor, At: at})
+	assert.NoError(t, err)
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210102T100000Z")
+	assert.Equal(t, []string{"20210102T103000Z"}, p.GetMatchingTimestamps(t1, t1.Add(time.Hour), tz))
+}
+
+func TestPeriod_AnchorErrors(t *testing.T) {
+	anchor, _ := ParseAnchor("2020-01-01T00:15")
+
+	// A cron expression is aligned to the clock
+	_, err := ParsePeriodWithOptions("0 9 * * *", Options{Anchor: anchor})
+	assert.Error(t, err)
+
+	// A rule with a DTSTART is already anchored
+	_, err = ParsePeriodWithOptions("DTSTART:20210101T090000Z\nRRULE:FREQ=DAILY", Options{Anchor: anchor})
+	assert.Error(t, err)
+}
+
+func TestPeriod_ParseAnchor(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Anchor
+	}{
+		{"", Anchor{}},
+		{"20200101T001500Z", Anchor{Time: time.Date(2020, 1, 1, 0, 15, 0, 0, time.UTC)}},
+		{"2020-01-01T00:15:00+02:00", Anchor{Time: time.Date(2019, 12, 31, 22, 15, 0, 0, time.UTC)}},
+		{"20200101T001500", Anchor{Time: time.Date(2020, 1, 1, 0, 15, 0, 0, time.UTC), Floating: true}},
+		{"2020-01-01T00:15", Anchor{Time: time.Date(2020, 1, 1, 0, 15, 0, 0, time.UTC), Floating: true}},
+		{"2020-01-01", Anchor{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Floating: true}},
+	}
+	for _, tt := range tests {
+		a, err := ParseAnchor(tt.s)
+		assert.NoError(t, err, tt.s)
+		assert.True(t, tt.expected.Time.Equal(a.Time), tt.s)
+		assert.Equal(t, tt.expected.Floating, a.Floating, tt.s)
+
+		// The string parses back to the same anchor
+		again, err := ParseAnchor(a.String())
+		assert.NoError(t, err, tt.s)
+		assert.True(t, a.Time.Equal(again.Time), tt.s)
+		assert.Equal(t, a.Floating, again.Floating, tt.s)
+	}
+	assert.Equal(t, "20191231T221500Z", Anchor{Time: time.Date(2020, 1, 1, 0, 15, 0, 0,
+		time.FixedZone("", 2*3600))}.String())
+	assert.Equal(t, "20200101T001500", Anchor{Time: time.Date(2020, 1, 1, 0, 15, 0, 0, time.UTC),
+		Floating: true}.String())
+
+	// The zero time is no anchor, and an offset moves the instant out of the
+	// supported years
+	for _, s := range []string{"2020", "01/01/2020", "20200101T0015Z", "00010101T000000Z",
+		"0001-01-01T00:15:00+02:00", "9999-12-31T23:00:00-02:00"} {
+		_, err := ParseAnchor(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,135 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Bounds defines which ends of a requested range [t1, t2] match. Every
+// period generates its timestamps in [t1, t2), and the bounds are applied
+// over it, so that they mean the same for all of them.
+type Bounds int
+
+const (
+	// ClosedOpen is [t1, t2), the default: t1 matches and t2 does not.
+	ClosedOpen Bounds = iota
+	// Closed is [t1, t2]: both t1 and t2 match.
+	Closed
+	// OpenClosed is (t1, t2]: t2 matches and t1 does not.
+	OpenClosed
+)
+
+// boundsNames are the names of the bounds, by their notation and by word.
+var boundsNames = map[string]Bounds{
+	"[)":          ClosedOpen,
+	"closed-open": ClosedOpen,
+	"[]":          Closed,
+	"closed":      Closed,
+	"(]":          OpenClosed,
+	"open-closed": OpenClosed,
+}
+
+// ParseBounds parses the bounds of a range, either in interval notation
+// ("[)", "[]" or "(]") or by name (closed-open, closed or open-closed). An
+// empty string is the default ClosedOpen.
+func ParseBounds(s string) (Bounds, error) {
+	if s == "" {
+		return ClosedOpen, nil
+	}
+	b, ok := boundsNames[s]
+	if !ok {
+		return ClosedOpen, fmt.Errorf("%q is not valid bounds (\"[)\", \"[]\" or \"(]\")", s)
+	}
+	return b, nil
+}
+
+func (b Bounds) String() string {
+	switch b {
+	case Closed:
+		return "[]"
+	case OpenClosed:
+		return "(]"
+	default:
+		return "[)"
+	}
+}
+
+// Contains reports whether t is in the range from t1 to t2.
+func (b Bounds) Contains(t, t1, t2 time.Time) bool {
+	switch b {
+	case Closed:
+		return !t.Before(t1) && !t.After(t2)
+	case OpenClosed:
+		return t.After(t1) && !t.After(t2)
+	default:
+		return !t.Before(t1) && t.Before(t2)
+	}
+}
+
+// Iter returns an iterator of the matching timestamps of p from t1 to t2.
+//
+// The range of p is only extended to t2, never shifted, so that the periods
+// that start at t1 (e.g. the ISO 8601 durations that are not anchored) keep
+// their phase, and just skip t1 when it is excluded.
+func (b Bounds) Iter(ctx context.Context, p Period, t1, t2 time.Time, tz *time.Location) Iterator {
+	if b == ClosedOpen {
+		return p.Iter(ctx, t1, t2, tz)
+	}
+	it := p.Iter(ctx, t1, t2.Add(time.Nanosecond), tz)
+	if b == Closed {
+		return it
+	}
+	return &openIterator{Iterator: it, t1: t1}
+}
+
+// Count returns the number of matching timestamps of p from t1 to t2 (see
+// Count).
+func (b Bounds) Count(ctx context.Context, p Period, t1, t2 time.Time, tz *time.Location) (int, error) {
+	if b == ClosedOpen {
+		return Count(ctx, p, t1, t2, tz)
+	}
+	n, err := Count(ctx, p, t1, t2.Add(time.Nanosecond), tz)
+	if err != nil || b == Closed || t2.Before(t1) {
+		return n, err
+	}
+
+	// Exclude t1, which is counted when it matches
+	m, err := Count(ctx, p, t1, t1.Add(time.Nanosecond), tz)
+	return n - m, err
+}
+
+// Exceeds reports whether p has more than max matching timestamps from t1
+// to t2. They are counted arithmetically where the period allows it, and
+// otherwise generated up to one more than max.
+func (b Bounds) Exceeds(ctx context.Context, p Period, t1, t2 time.Time, tz *time.Location,
+	max int) (bool, error) {
+	if c, ok := p.(counter); ok {
+		if _, ok := c.count(ctx, t1, t2, tz); ok {
+			n, err := b.Count(ctx, p, t1, t2, tz)
+			return n > max, err
+		}
+	}
+
+	n := 0
+	it := b.Iter(ctx, p, t1, t2, tz)
+	for n <= max && it.Next() {
+		n++
+	}
+	return n > max, it.Err()
+}
+
+// openIterator skips the timestamp at the excluded start of its range.
+type openIterator struct {
+	Iterator
+	t1 time.Time
+}
+
+func (it *openIterator) Next() bool {
+	for it.Iterator.Next() {
+		if it.Time().After(it.t1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Bounds(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	// Both ends are timestamps of every period below
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210713T210000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210714T210000Z")
+
+	periods := []string{
+		ONEHOUR, ONEDAY, "15m", "6h", "PT1H", "P1D", "0 0 * * *", "@hourly",
+		"FREQ=HOURLY;INTERVAL=3",
+		`{"union": [{"period": "1d"}, {"period": "12h"}]}`,
+		`{"within": {"from": "00:00", "to": "01:00"}, "schedule": {"period": "1h"}}`,
+	}
+	for _, s := range periods {
+		t.Run(s, func(t *testing.T) {
+			p, err := ParsePeriod(s)
+			assert.NoError(t, err)
+
+			// The bounds only add or drop the ends of [t1, t2)
+			base := MatchingTimes(p, t1, t2, tz)
+			if assert.NotEmpty(t, base) {
+				assert.True(t, base[0].Equal(t1), "Expected t1 to match")
+			}
+			expected := map[Bounds][]time.Time{
+				ClosedOpen: base,
+				Closed:     append(append([]time.Time{}, base...), t2.In(tz)),
+				OpenClosed: append(append([]time.Time{}, base[1:]...), t2.In(tz)),
+			}
+
+			for b, list := range expected {
+				got, err := Collect(b.Iter(context.Background(), p, t1, t2, tz))
+				assert.NoError(t, err)
+				assert.Equal(t, list, got, "%s", b)
+
+				n, err := b.Count(context.Background(), p, t1, t2, tz)
+				assert.NoError(t, err)
+				assert.Equal(t, len(list), n, "%s", b)
+
+				for _, ts := range got {
+					assert.True(t, b.Contains(ts, t1, t2), "%s %s", b, ts)
+				}
+			}
+		})
+	}
+}
+
+func TestPeriod_BoundsEmptyRange(t *testing.T) {
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210714T000000Z")
+	p := OneHourPeriod{}
+
+	// [t1, t1] is t1 alone, while [t1, t1) and (t1, t1] are empty
+	for b, expected := range map[Bounds]int{ClosedOpen: 0, Closed: 1, OpenClosed: 0} {
+		n, err := b.Count(context.Background(), p, t1, t1, time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, n, "%s", b)
+
+		list, err := Collect(b.Iter(context.Background(), p, t1, t1, time.UTC))
+		assert.NoError(t, err)
+		assert.Len(t, list, expected, "%s", b)
+	}
+}
+
+func TestPeriod_BoundsExceeds(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210713T210000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210714T210000Z")
+
+	// 24 timestamps in [t1, t2), counted or generated, and 25 in [t1, t2]
+	for _, s := range []string{ONEHOUR, "0 * * * *"} {
+		p, err := ParsePeriod(s)
+		assert.NoError(t, err)
+
+		for max, expected := range map[int]bool{23: true, 24: false, 1000: false} {
+			exceeds, err := ClosedOpen.Exceeds(context.Background(), p, t1, t2, tz, max)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, exceeds, "%s %d", s, max)
+		}
+		exceeds, err := Closed.Exceeds(context.Background(), p, t1, t2, tz, 24)
+		assert.NoError(t, err)
+		assert.True(t, exceeds, s)
+	}
+
+	// The timestamps are not generated further than one more than max
+	p, _ := ParsePeriod("* * * * *")
+	far := t1.AddDate(1000, 0, 0)
+	exceeds, err := ClosedOpen.Exceeds(context.Background(), p, t1, far, tz, 10)
+	assert.NoError(t, err)
+	assert.True(t, exceeds)
+}
+
+func TestPeriod_ParseBounds(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Bounds
+	}{
+		{"", ClosedOpen},
+		{"[)", ClosedOpen},
+		{"closed-open", ClosedOpen},
+		{"[]", Closed},
+		{"closed", Closed},
+		{"(]", OpenClosed},
+		{"open-closed", OpenClosed},
+	}
+	for _, tt := range tests {
+		b, err := ParseBounds(tt.s)
+		assert.NoError(t, err, tt.s)
+		assert.Equal(t, tt.expected, b, tt.s)
+	}
+
+	for _, s := range []string{"()", "open", "[", "[ )"} {
+		_, err := ParseBounds(s)
+		assert.Error(t, err, s)
+	}
+}
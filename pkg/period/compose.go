@@ -0,0 +1,251 @@
+package period
+
+import (
+	"context"
+	"time"
+)
+
+// Union Period, the timestamps of any of its periods
+// (e.g. every day plus the last day of each quarter)
+type UnionPeriod struct {
+	Periods []Period
+}
+
+func (up UnionPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(up.Iter(context.Background(), t1, t2, tz))
+}
+
+func (up UnionPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	cs := cursors(ctx, up.Periods, t1, t2, tz)
+
+	// Merge the timestamps of the periods, the earliest one at a time
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		var first *cursor
+		for _, c := range cs {
+			t, ok, err := c.head()
+			if err != nil {
+				return nil, t1, false, err
+			}
+			if ok && (first == nil || t.Before(first.t)) {
+				first = c
+			}
+		}
+		if first == nil {
+			return nil, t2, false, nil
+		}
+
+		t := first.t
+		first.next()
+		return []time.Time{t}, t, true, nil
+	})
+}
+
+// Intersect Period, the timestamps of all of its periods
+// (e.g. the first business day that is also a Monday)
+type IntersectPeriod struct {
+	Periods []Period
+}
+
+func (ip IntersectPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(ip.Iter(context.Background(), t1, t2, tz))
+}
+
+func (ip IntersectPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	cs := cursors(ctx, ip.Periods, t1, t2, tz)
+
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		if len(cs) == 0 {
+			return nil, t2, false, nil
+		}
+		for {
+			// Every period has to reach the latest of their timestamps
+			var latest time.Time
+			for _, c := range cs {
+				t, ok, err := c.head()
+				if err != nil || !ok {
+					return nil, t2, false, err
+				}
+				if t.After(latest) {
+					latest = t
+				}
+			}
+
+			all := true
+			for _, c := range cs {
+				t, ok, err := c.seek(latest)
+				if err != nil || !ok {
+					return nil, t2, false, err
+				}
+				all = all && t.Equal(latest)
+			}
+			if all {
+				for _, c := range cs {
+					c.next()
+				}
+				return []time.Time{latest}, latest, true, nil
+			}
+		}
+	})
+}
+
+// Except Period, the timestamps of a period that are not timestamps of the
+// excluded period
+type ExceptPeriod struct {
+	Period   Period
+	Excluded Period
+}
+
+func (ep ExceptPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(ep.Iter(context.Background(), t1, t2, tz))
+}
+
+func (ep ExceptPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	cs := cursors(ctx, []Period{ep.Period, ep.Excluded}, t1, t2, tz)
+	base, excluded := cs[0], cs[1]
+
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		t, ok, err := base.head()
+		if err != nil || !ok {
+			return nil, t2, false, err
+		}
+		base.next()
+
+		// Skip the excluded timestamps before t
+		u, ok, err := excluded.seek(t)
+		if err != nil {
+			return nil, t1, false, err
+		}
+		if ok && u.Equal(t) {
+			return nil, t, true, nil
+		}
+		return []time.Time{t}, t, true, nil
+	})
+}
+
+// Window is a recurring time window on the wall clock, e.g. from 00:00 to
+// 06:00 on weekends, optionally within a range of dates.
+type Window struct {
+	// Weekdays are the days of the window, every day when empty.
+	Weekdays []time.Weekday
+	// From and To are the times of the day of the window [From, To), the
+	// whole day when both are zero. A window with To before From ends on the
+	// next day, e.g. from 22:00 to 06:00, and belongs to the day it starts.
+	From, To time.Duration
+	// Start and End are the first and the last day of the window, civil
+	// dates in UTC. A zero date leaves the window open.
+	Start, End time.Time
+}
+
+// Contains reports whether the wall clock of t is inside the window.
+func (w Window) Contains(t time.Time) bool {
+	c := toCivil(t)
+	y, m, d := c.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	clock := c.Sub(day)
+
+	switch {
+	case w.From < w.To:
+		if clock < w.From || clock >= w.To {
+			return false
+		}
+	case w.From > w.To:
+		// The early hours belong to the window of the previous day
+		if clock < w.To {
+			day = day.AddDate(0, 0, -1)
+		} else if clock < w.From {
+			return false
+		}
+	}
+
+	if !w.Start.IsZero() && day.Before(w.Start) || !w.End.IsZero() && day.After(w.End) {
+		return false
+	}
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, wd := range w.Weekdays {
+		if day.Weekday() == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// Window Period, the timestamps of a period inside a time window, or
+// outside of it (e.g. every hour, except from 00:00 to 06:00 on weekends)
+type WindowPeriod struct {
+	Period Period
+	Window Window
+	// Outside keeps the timestamps outside of the window instead.
+	Outside bool
+}
+
+func (wp WindowPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(wp.Iter(context.Background(), t1, t2, tz))
+}
+
+func (wp WindowPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	c := cursors(ctx, []Period{wp.Period}, t1, t2, tz)[0]
+
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		t, ok, err := c.head()
+		if err != nil || !ok {
+			return nil, t2, false, err
+		}
+		c.next()
+
+		// The window is on the wall clock of the requested timezone
+		if wp.Window.Contains(t.In(tz)) == wp.Outside {
+			return nil, t, true, nil
+		}
+		return []time.Time{t}, t, true, nil
+	})
+}
+
+// cursor holds the current timestamp of the iterator of a composed period,
+// which starts lazily.
+type cursor struct {
+	it      Iterator
+	t       time.Time
+	ok      bool
+	started bool
+}
+
+// cursors returns the cursors of the periods in [t1, t2).
+func cursors(ctx context.Context, periods []Period, t1, t2 time.Time,
+	tz *time.Location) []*cursor {
+	cs := make([]*cursor, 0, len(periods))
+	for _, p := range periods {
+		cs = append(cs, &cursor{it: p.Iter(ctx, t1, t2, tz)})
+	}
+	return cs
+}
+
+// head returns the current timestamp, or false after the last one.
+func (c *cursor) head() (time.Time, bool, error) {
+	if !c.started {
+		c.started = true
+		c.next()
+	}
+	if !c.ok {
+		return c.t, false, c.it.Err()
+	}
+	return c.t, true, nil
+}
+
+// next advances to the next timestamp.
+func (c *cursor) next() {
+	if c.ok = c.it.Next(); c.ok {
+		c.t = c.it.Time()
+	}
+}
+
+// seek advances to the first timestamp at or after t.
+func (c *cursor) seek(t time.Time) (time.Time, bool, error) {
+	u, ok, err := c.head()
+	for ok && u.Before(t) {
+		c.next()
+		u, ok, err = c.head()
+	}
+	return u, ok, err
+}
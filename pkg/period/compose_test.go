@@ -0,0 +1,160 @@
+package period
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Schedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		t1       string
+		t2       string
+		expected []string
+	}{
+		{
+			name: "Daily plus the last day of each quarter",
+			schedule: `{"union": [{"period": "1d"},
+				{"period": "1q", "at": "month -1 day -1 at 18:00"}]}`,
+			t1: "20210330T000000Z",
+			t2: "20210402T000000Z",
+			expected: []string{
+				"20210330T000000Z",
+				"20210331T000000Z",
+				"20210331T180000Z",
+				"20210401T000000Z",
+			},
+		},
+		{
+			name:     "Union without duplicates",
+			schedule: `{"union": [{"period": "1h"}, {"period": "2h"}]}`,
+			t1:       "20210729T000000Z",
+			t2:       "20210729T030000Z",
+			expected: []string{"20210729T000000Z", "20210729T010000Z", "20210729T020000Z"},
+		},
+		{
+			name:     "Intersection",
+			schedule: `{"intersect": [{"period": "1h"}, {"period": "0 */3 * * *"}]}`,
+			t1:       "20210729T000000Z",
+			t2:       "20210729T070000Z",
+			expected: []string{"20210729T000000Z", "20210729T030000Z", "20210729T060000Z"},
+		},
+		{
+			name:     "Exclusion",
+			schedule: `{"except": [{"period": "1h"}, {"period": "2h"}]}`,
+			t1:       "20210729T000000Z",
+			t2:       "20210729T050000Z",
+			expected: []string{"20210729T010000Z", "20210729T030000Z"},
+		},
+		{
+			name:     "Exclusion of several schedules",
+			schedule: `{"except": [{"period": "1h"}, {"period": "2h"}, {"period": "0 3 * * *"}]}`,
+			t1:       "20210729T000000Z",
+			t2:       "20210729T050000Z",
+			expected: []string{"20210729T010000Z"},
+		},
+		{
+			name: "Every hour, except from 00:00 to 06:00 on weekends",
+			schedule: `{"outside": {"weekdays": ["sat", "sun"], "from": "00:00", "to": "06:00"},
+				"schedule": {"period": "1h"}}`,
+			t1: "20210730T220000Z",
+			t2: "20210731T080000Z",
+			expected: []string{
+				"20210730T220000Z",
+				"20210730T230000Z",
+				"20210731T060000Z",
+				"20210731T070000Z",
+			},
+		},
+		{
+			name: "Window across midnight",
+			schedule: `{"within": {"weekdays": ["friday"], "from": "22:00", "to": "02:00"},
+				"schedule": {"period": "1h"}}`,
+			t1: "20210730T200000Z",
+			t2: "20210731T040000Z",
+			expected: []string{
+				"20210730T220000Z",
+				"20210730T230000Z",
+				"20210731T000000Z",
+				"20210731T010000Z",
+			},
+		},
+		{
+			name: "Window of dates",
+			schedule: `{"within": {"start": "2021-07-02", "end": "2021-07-03"},
+				"schedule": {"period": "1d"}}`,
+			t1:       "20210701T000000Z",
+			t2:       "20210706T000000Z",
+			expected: []string{"20210702T000000Z", "20210703T000000Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			p, err := ParsePeriod(tt.schedule)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, time.UTC))
+		})
+	}
+}
+
+func TestPeriod_ScheduleInLocalTime(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210730T190000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210730T230000Z")
+
+	// The window is on the wall clock of the requested timezone (UTC+3)
+	p := WindowPeriod{
+		Period: OneHourPeriod{},
+		Window: Window{From: 22 * time.Hour, To: 24 * time.Hour},
+	}
+	assert.Equal(t, []string{"20210730T190000Z", "20210730T200000Z"},
+		p.GetMatchingTimestamps(t1, t2, tz))
+}
+
+func TestPeriod_ScheduleErrors(t *testing.T) {
+	deep := strings.Repeat(`{"union": [`, 40) + `{"period": "1h"}` + strings.Repeat(`]}`, 40)
+
+	for _, s := range []string{
+		`{}`,
+		`{"period": "1h", "union": [{"period": "1d"}]}`,
+		`{"period": "1h", "every": "day"}`,
+		`{"union": []}`,
+		`{"except": [{"period": "1h"}]}`,
+		`{"union": [{"period": "1x"}]}`,
+		`{"union": [{"period": "1h"}], "at": "end"}`,
+		`{"within": {"weekdays": ["someday"]}, "schedule": {"period": "1h"}}`,
+		`{"within": {"from": "25:00"}, "schedule": {"period": "1h"}}`,
+		`{"within": {"start": "2021-13-01"}, "schedule": {"period": "1h"}}`,
+		`{"within": {}}`,
+		`{"period": "1h", "schedule": {"period": "1d"}}`,
+		`{"period": "1h"`,
+		deep,
+	} {
+		_, err := ParsePeriod(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPeriod_ScheduleCancel(t *testing.T) {
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2 := t1.AddDate(1000, 0, 0)
+
+	p := ExceptPeriod{Period: OneHourPeriod{}, Excluded: OneDayPeriod{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := p.Iter(ctx, t1, t2, time.UTC)
+	assert.True(t, it.Next())
+
+	cancel()
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+}
@@ -0,0 +1,52 @@
+package period
+
+import (
+	"context"
+	"time"
+)
+
+// counter is implemented by the periods that count their timestamps
+// arithmetically. It reports false when the timestamps have to be generated,
+// or when ctx is done.
+type counter interface {
+	count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool)
+}
+
+// maxCorrections bounds the periods that an arithmetic estimate is corrected
+// by, beyond which the estimate is given up.
+const maxCorrections = 64
+
+// Count returns the number of matching timestamps of p in [t1, t2). It is
+// computed arithmetically where the period allows it, and otherwise by
+// iterating over the timestamps without keeping them.
+func Count(ctx context.Context, p Period, t1, t2 time.Time, tz *time.Location) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if c, ok := p.(counter); ok {
+		if n, ok := c.count(ctx, t1, t2, tz); ok {
+			return n, nil
+		}
+	}
+
+	var n int
+	it := p.Iter(ctx, t1, t2, tz)
+	for it.Next() {
+		n++
+	}
+	return n, it.Err()
+}
+
+// Matches reports whether t is a matching timestamp of p, in the requested
+// timezone.
+//
+// The ISO 8601 durations and the recurrence rules without a DTSTART that
+// depend on their start would start at t, and always match, so like Next it
+// returns ErrNoPhase for them unless they are anchored.
+func Matches(ctx context.Context, p Period, t time.Time, tz *time.Location) (bool, error) {
+	if !phased(p) {
+		return false, ErrNoPhase
+	}
+	n, err := Count(ctx, p, t, t.Add(time.Nanosecond), tz)
+	return n > 0, err
+}
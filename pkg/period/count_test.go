@@ -0,0 +1,195 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Count(t *testing.T) {
+	ranges := [][2]string{
+		{"20210101T000000Z", "20220101T000000Z"},
+		{"20210328T003000Z", "20210328T020000Z"},
+		{"20211031T000000Z", "20211031T030000Z"},
+		{"20210214T204603Z", "20230615T123456Z"},
+		{"20210101T000000Z", "20210101T000000Z"},
+	}
+
+	tests := []struct {
+		period string
+		at     string
+		dst    string
+	}{
+		{period: ONEHOUR},
+		{period: ONEDAY},
+		{period: ONEWEEK},
+		{period: ONEMONTH},
+		{period: ONEQUARTER},
+		{period: ONEYEAR},
+		{period: "15m"},
+		{period: "15m", dst: "wall"},
+		{period: "6h", at: "minute 30"},
+		{period: "2d", at: "end"},
+		{period: "2d", dst: "elapsed"},
+		{period: "1d", at: "at 03:30", dst: "last"},
+		{period: "1d", at: "at 03:30", dst: "skip"},
+		{period: "1d", at: "at 03:30", dst: "both"},
+		{period: "1mo", at: "day -1 at 18:00"},
+		{period: "1mo", at: "last friday"},
+		{period: "3mo", at: "month 2 day 15"},
+		{period: "PT1H"},
+		{period: "PT1H", at: "end"},
+		{period: "P1DT12H"},
+		{period: "P1DT12H", dst: "elapsed"},
+		{period: "P1M"},
+		{period: "0 9 * * 1-5"},
+		{period: "FREQ=MONTHLY;BYDAY=-1FR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period+" "+tt.at+" "+tt.dst, func(t *testing.T) {
+			at, err := ParseInvocation(tt.at)
+			assert.NoError(t, err)
+			dst, err := ParseDST(tt.dst)
+			assert.NoError(t, err)
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at, DST: dst})
+			assert.NoError(t, err)
+
+			// The count agrees with the generated timestamps
+			for _, tz := range []string{"UTC", "Europe/Athens", "America/New_York"} {
+				loc, _ := time.LoadLocation(tz)
+				for _, r := range ranges {
+					t1, _ := time.Parse(SUPPORTEDFORMAT, r[0])
+					t2, _ := time.Parse(SUPPORTEDFORMAT, r[1])
+
+					n, err := Count(context.Background(), p, t1, t2, loc)
+					assert.NoError(t, err)
+					assert.Equal(t, len(MatchingTimes(p, t1, t2, loc)), n, "%s %v", tz, r)
+				}
+			}
+		})
+	}
+}
+
+func TestPeriod_CountIsArithmetic(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2 := t1.AddDate(200, 0, 0)
+
+	// Two hundred years of minutes are never generated
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	n, err := Count(ctx, MultiplePeriod{N: 1, Unit: Minute}, t1, t2, tz)
+	assert.NoError(t, err)
+	assert.Equal(t, int(t2.Sub(t1)/time.Minute), n)
+
+	n, err = Count(ctx, OneMonthPeriod{}, t1, t2, tz)
+	assert.NoError(t, err)
+	assert.Equal(t, 2400, n)
+}
+
+func TestPeriod_Matches(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		t        string
+		expected bool
+	}{
+		{
+			name:     "Start of an hour",
+			period:   ONEHOUR,
+			t:        "20210714T210000Z",
+			expected: true,
+		},
+		{
+			name:     "Inside an hour",
+			period:   ONEHOUR,
+			t:        "20210714T204603Z",
+			expected: false,
+		},
+		{
+			name:     "End of a month in local time",
+			period:   ONEMONTH,
+			t:        "20210228T220000Z",
+			expected: true,
+		},
+		{
+			name:     "End of a month in UTC",
+			period:   ONEMONTH,
+			t:        "20210228T000000Z",
+			expected: false,
+		},
+		{
+			name:     "Cron fire time",
+			period:   "0 9 * * 1-5",
+			t:        "20211101T070000Z",
+			expected: true,
+		},
+		{
+			name:     "Cron on the weekend",
+			period:   "0 9 * * 1-5",
+			t:        "20211031T070000Z",
+			expected: false,
+		},
+		{
+			name:     "Rule without a DTSTART that does not depend on it",
+			period:   "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9",
+			t:        "20211101T070000Z",
+			expected: true,
+		},
+		{
+			name:     "Rule without a DTSTART off its hours",
+			period:   "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9",
+			t:        "20211101T073000Z",
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, _ := time.Parse(SUPPORTEDFORMAT, tt.t)
+			p, err := ParsePeriod(tt.period)
+			assert.NoError(t, err)
+
+			ok, err := Matches(context.Background(), p, ts, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
+
+func TestPeriod_MatchesPhase(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	ts, _ := time.Parse(SUPPORTEDFORMAT, "20210714T123417Z")
+
+	// Without a phase, any time would match
+	for _, s := range []string{"PT1H", "P1D", "FREQ=MINUTELY;INTERVAL=7", "2d", "5w", "7h"} {
+		p, err := ParsePeriod(s)
+		assert.NoError(t, err)
+
+		_, err = Matches(context.Background(), p, ts, tz)
+		assert.ErrorIs(t, err, ErrNoPhase, s)
+	}
+
+	// The anchor gives them one, e.g. every other day from the anchor
+	anchor, _ := ParseAnchor("20210101T000000Z")
+	midnight, _ := ParseAnchor("2021-01-01")
+	p, err := ParsePeriodWithOptions("2d", Options{Anchor: midnight})
+	assert.NoError(t, err)
+	for day, expected := range map[int]bool{12: true, 13: false} {
+		ok, err := Matches(context.Background(), p, time.Date(2021, 7, day, 0, 0, 0, 0, tz), tz)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, day)
+	}
+
+	p, err = ParsePeriodWithOptions("PT1H", Options{Anchor: anchor})
+	assert.NoError(t, err)
+	for t1, expected := range map[time.Time]bool{ts: false, ts.Truncate(time.Hour): true} {
+		ok, err := Matches(context.Background(), p, t1, tz)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, t1)
+	}
+}
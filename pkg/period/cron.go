@@ -0,0 +1,285 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros maps the supported cron macros to their 5-field expressions.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the bounds and the names of a cron field.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 are Sunday
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronSearchYears bounds the search of the next fire time, so that
+// expressions that never fire (e.g. 30 February) terminate. It is the longest
+// gap between two fire times of an expression, 29 February of 2096 and 2104.
+const cronSearchYears = 8
+
+// Cron Period, described by a 5-field or 6-field (with seconds) cron
+// expression or a macro such as @daily, evaluated in the requested timezone.
+type CronPeriod struct {
+	Expr string
+
+	second, minute, hour, dom, month, dow uint64
+	// A restricted day of month and day of week match either of them,
+	// following the behaviour of the standard cron.
+	domStar, dowStar bool
+
+	// DST is the daylight saving time policy. A cron expression matches the
+	// wall clock, so only its gap and fold policies apply.
+	DST DST
+}
+
+// NewCronPeriod parses a cron expression.
+func NewCronPeriod(expr string) (CronPeriod, error) {
+	cp := CronPeriod{Expr: expr}
+
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		m, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return cp, fmt.Errorf("%q is not a supported cron macro", expr)
+		}
+		spec = m
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		// Fire at the start of the minute
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return cp, fmt.Errorf("%q should have 5 or 6 fields, but has %d",
+			expr, len(fields))
+	}
+
+	var err error
+	if cp.second, _, err = parseCronField(fields[0], secondField); err != nil {
+		return cp, err
+	}
+	if cp.minute, _, err = parseCronField(fields[1], minuteField); err != nil {
+		return cp, err
+	}
+	if cp.hour, _, err = parseCronField(fields[2], hourField); err != nil {
+		return cp, err
+	}
+	if cp.dom, cp.domStar, err = parseCronField(fields[3], domField); err != nil {
+		return cp, err
+	}
+	if cp.month, _, err = parseCronField(fields[4], monthField); err != nil {
+		return cp, err
+	}
+	if cp.dow, cp.dowStar, err = parseCronField(fields[5], dowField); err != nil {
+		return cp, err
+	}
+	// Sunday can be written either as 0 or as 7
+	if cp.dow&(1<<7) != 0 {
+		cp.dow |= 1
+	}
+
+	return cp, nil
+}
+
+func (cp CronPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(cp.Iter(context.Background(), t1, t2, tz))
+}
+
+func (cp CronPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	// Match the wall clock of the requested timezone (civil times in UTC)
+	// from a day before t1, since the instants of a wall clock may be on
+	// either side of it, and turn the fire times into instants by the DST
+	// policy
+	c := toCivil(t1.In(tz)).Add(-dstMargin)
+
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		next, ok := cp.next(c)
+		if !ok {
+			return nil, time.Time{}, false, nil
+		}
+		c = next.Add(time.Second)
+		return cp.DST.resolve(next, tz), c.Add(-dstMargin), true, nil
+	})
+}
+
+func (cp CronPeriod) withDST(dst DST) Period {
+	cp.DST = dst
+	return cp
+}
+
+// next returns the first fire time at or after the wall clock c, as a civil
+// time in UTC. It reports false if there is none within the next
+// cronSearchYears years.
+func (cp CronPeriod) next(c time.Time) (time.Time, bool) {
+	// Round up to the next whole second
+	if c.Nanosecond() > 0 {
+		c = c.Add(time.Second - time.Duration(c.Nanosecond()))
+	}
+	yearLimit := c.Year() + cronSearchYears
+
+	// Move forward field by field, from the month to the second, and start
+	// over whenever a field wraps around, since the larger field changed
+wrap:
+	if c.Year() > yearLimit {
+		return time.Time{}, false
+	}
+
+	for cp.month&(1<<uint(c.Month())) == 0 {
+		c = time.Date(c.Year(), c.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		if c.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !cp.dayMatches(c) {
+		c = time.Date(c.Year(), c.Month(), c.Day()+1, 0, 0, 0, 0, time.UTC)
+		if c.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for cp.hour&(1<<uint(c.Hour())) == 0 {
+		c = c.Truncate(time.Hour).Add(time.Hour)
+		if c.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for cp.minute&(1<<uint(c.Minute())) == 0 {
+		c = c.Truncate(time.Minute).Add(time.Minute)
+		if c.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for cp.second&(1<<uint(c.Second())) == 0 {
+		c = c.Add(time.Second)
+		if c.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return c, true
+}
+
+// dayMatches reports whether the day of t matches the day of month and
+// the day of week fields.
+func (cp CronPeriod) dayMatches(t time.Time) bool {
+	domMatch := cp.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cp.dow&(1<<uint(t.Weekday())) != 0
+	if cp.domStar || cp.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField parses a comma separated list of values, ranges (a-b) and
+// steps (*/n, a-b/n, a/n) into a bit set. It also reports whether the field
+// starts with * or ?, which Vixie cron treats as unrestricted in the day
+// fields, even with a step (*/2).
+func parseCronField(s string, f cronField) (uint64, bool, error) {
+	if s == "*" || s == "?" {
+		return rangeBits(f.min, f.max, 1), true, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		expr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("%q has an invalid step in the %s field",
+					s, f.name)
+			}
+			expr, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case expr == "*" || expr == "?":
+		case strings.Contains(expr, "-"):
+			i := strings.Index(expr, "-")
+			var err error
+			if lo, err = f.value(expr[:i]); err != nil {
+				return 0, false, fmt.Errorf("%q: %v", s, err)
+			}
+			if hi, err = f.value(expr[i+1:]); err != nil {
+				return 0, false, fmt.Errorf("%q: %v", s, err)
+			}
+			if lo > hi {
+				return 0, false, fmt.Errorf("%q has an empty range in the %s field",
+					s, f.name)
+			}
+		default:
+			v, err := f.value(expr)
+			if err != nil {
+				return 0, false, fmt.Errorf("%q: %v", s, err)
+			}
+			lo = v
+			// A single value is a range only when it has a step (a/n)
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		bits |= rangeBits(lo, hi, step)
+	}
+
+	return bits, s[0] == '*' || s[0] == '?', nil
+}
+
+// value parses a number or a name of the field and checks its bounds.
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid %s", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d is out of range for the %s field (%d-%d)",
+			v, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// rangeBits returns a bit set with the bits lo, lo+step, ... up to hi.
+func rangeBits(lo, hi, step int) uint64 {
+	var bits uint64
+	for i := lo; i <= hi; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits
+}
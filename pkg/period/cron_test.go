@@ -0,0 +1,193 @@
+package period
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Cron(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		expr     string
+		dst      string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name: "Weekdays at 09:00 across the DST change",
+			expr: "0 9 * * 1-5",
+			t1:   "20211028T060000Z",
+			t2:   "20211102T000000Z",
+			expected: []string{
+				"20211028T060000Z", "20211029T060000Z", "20211101T070000Z",
+			},
+		},
+		{
+			name: "Every 15 minutes",
+			expr: "*/15 * * * *",
+			t1:   "20210714T204603Z",
+			t2:   "20210714T220000Z",
+			expected: []string{
+				"20210714T210000Z", "20210714T211500Z", "20210714T213000Z",
+				"20210714T214500Z",
+			},
+		},
+		{
+			name: "With seconds",
+			expr: "30 */20 9 * * *",
+			t1:   "20210714T000000Z",
+			t2:   "20210715T000000Z",
+			expected: []string{
+				"20210714T060030Z", "20210714T062030Z", "20210714T064030Z",
+			},
+		},
+		{
+			name: "Monthly macro",
+			expr: "@monthly",
+			t1:   "20210214T214603Z",
+			t2:   "20210601T000000Z",
+			expected: []string{
+				"20210228T220000Z", "20210331T210000Z", "20210430T210000Z",
+				"20210531T210000Z",
+			},
+		},
+		{
+			name: "Day of month or day of week",
+			expr: "0 12 13 * fri",
+			t1:   "20210801T000000Z",
+			t2:   "20210901T000000Z",
+			expected: []string{
+				"20210806T090000Z", "20210813T090000Z", "20210820T090000Z",
+				"20210827T090000Z",
+			},
+		},
+		{
+			name: "Day of month or day of week on different days",
+			expr: "0 12 14 * FRI",
+			t1:   "20210801T000000Z",
+			t2:   "20210816T000000Z",
+			expected: []string{
+				"20210806T090000Z", "20210813T090000Z", "20210814T090000Z",
+			},
+		},
+		{
+			name: "Stepped day of month and day of week",
+			expr: "0 12 */2 * fri",
+			t1:   "20210801T000000Z",
+			t2:   "20210901T000000Z",
+			expected: []string{
+				"20210813T090000Z", "20210827T090000Z",
+			},
+		},
+		{
+			name:     "Day of month and stepped day of week",
+			expr:     "0 12 13 * */2",
+			t1:       "20210801T000000Z",
+			t2:       "20220101T000000Z",
+			expected: []string{"20211113T100000Z"},
+		},
+		{
+			name: "Nonexistent local time is shifted forward",
+			expr: "30 3 * * *",
+			t1:   "20210326T000000Z",
+			t2:   "20210330T000000Z",
+			expected: []string{
+				"20210326T013000Z", "20210327T013000Z", "20210328T013000Z",
+				"20210329T003000Z",
+			},
+		},
+		{
+			name: "Nonexistent local time is skipped",
+			expr: "30 3 * * *",
+			dst:  "skip",
+			t1:   "20210326T000000Z",
+			t2:   "20210330T000000Z",
+			expected: []string{
+				"20210326T013000Z", "20210327T013000Z", "20210329T003000Z",
+			},
+		},
+		{
+			name: "Ambiguous local time fires once",
+			expr: "30 3 * * *",
+			t1:   "20211030T000000Z",
+			t2:   "20211102T000000Z",
+			expected: []string{
+				"20211030T003000Z", "20211031T003000Z", "20211101T013000Z",
+			},
+		},
+		{
+			name: "Ambiguous local time fires at the last occurrence",
+			expr: "30 3 * * *",
+			dst:  "last",
+			t1:   "20211030T000000Z",
+			t2:   "20211102T000000Z",
+			expected: []string{
+				"20211030T003000Z", "20211031T013000Z", "20211101T013000Z",
+			},
+		},
+		{
+			name: "Ambiguous local time fires twice",
+			expr: "30 3 * * *",
+			dst:  "both",
+			t1:   "20211030T000000Z",
+			t2:   "20211102T000000Z",
+			expected: []string{
+				"20211030T003000Z", "20211031T003000Z", "20211031T013000Z",
+				"20211101T013000Z",
+			},
+		},
+		{
+			name: "29 February across 2100, which is not a leap year",
+			expr: "0 0 29 2 *",
+			t1:   "20920101T000000Z",
+			t2:   "21090101T000000Z",
+			expected: []string{
+				"20920228T220000Z", "20960228T220000Z", "21040228T220000Z",
+				"21080228T220000Z",
+			},
+		},
+		{
+			name:     "Never fires",
+			expr:     "0 0 30 2 *",
+			t1:       "20210101T000000Z",
+			t2:       "20301231T000000Z",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			dst, err := ParseDST(tt.dst)
+			assert.NoError(t, err)
+			p, err := ParsePeriodWithOptions(tt.expr, Options{DST: dst})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_InvalidCron(t *testing.T) {
+	for _, expr := range []string{
+		"@fortnightly",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParsePeriod(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,224 @@
+package period
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stepping tells how a period steps across a daylight saving time change.
+type Stepping int
+
+// Constants for all supported steppings
+const (
+	// DefaultStepping keeps the wall clock for the days and the longer
+	// units, and the elapsed time for the minutes and the hours.
+	DefaultStepping Stepping = iota
+	// WallStepping keeps the wall clock, e.g. 1h steps 01:00, 02:00, 03:00
+	// and 1d steps at midnight, even if an hour is skipped or repeated.
+	WallStepping
+	// ElapsedStepping keeps the elapsed time, e.g. 1d steps 24 hours, which
+	// moves the wall clock by an hour after a change.
+	ElapsedStepping
+)
+
+// GapPolicy tells what happens to the local times that do not exist, since
+// the clock moves forward (e.g. 03:30 when 03:00 becomes 04:00).
+type GapPolicy int
+
+// Constants for all supported gap policies
+const (
+	// ShiftGap shifts the time forward by the length of the gap (03:30
+	// becomes 04:30), as RFC 5545 does.
+	ShiftGap GapPolicy = iota
+	// SkipGap drops the time.
+	SkipGap
+)
+
+// FoldPolicy tells what happens to the local times that occur twice, since
+// the clock moves backward (e.g. 03:30 when 04:00 becomes 03:00).
+type FoldPolicy int
+
+// Constants for all supported fold policies
+const (
+	// FirstFold keeps the first occurrence, as RFC 5545 does.
+	FirstFold FoldPolicy = iota
+	// LastFold keeps the second occurrence.
+	LastFold
+	// BothFolds keeps both occurrences.
+	BothFolds
+)
+
+// DST is the daylight saving time policy of a period. The zero value steps
+// naturally, shifts the nonexistent times and keeps the first occurrence of
+// the ambiguous ones.
+type DST struct {
+	Stepping Stepping
+	Gap      GapPolicy
+	Fold     FoldPolicy
+}
+
+var dstTokens = map[string]func(*DST){
+	"wall":    func(p *DST) { p.Stepping = WallStepping },
+	"elapsed": func(p *DST) { p.Stepping = ElapsedStepping },
+	"shift":   func(p *DST) { p.Gap = ShiftGap },
+	"skip":    func(p *DST) { p.Gap = SkipGap },
+	"first":   func(p *DST) { p.Fold = FirstFold },
+	"last":    func(p *DST) { p.Fold = LastFold },
+	"both":    func(p *DST) { p.Fold = BothFolds },
+}
+
+// dstMargin bounds how far the wall clock is from the UTC offset of a
+// nearby instant, which covers the largest offset changes (e.g. a timezone
+// that moves across the date line).
+const dstMargin = 24 * time.Hour
+
+// ParseDST parses a comma separated daylight saving time policy, such as
+// "wall,skip,both", made of a stepping (wall or elapsed), a gap policy (shift
+// or skip) and a fold policy (first, last or both). The omitted parts keep
+// their default.
+func ParseDST(s string) (DST, error) {
+	var p DST
+	if strings.TrimSpace(s) == "" {
+		return p, nil
+	}
+	for _, token := range strings.Split(s, ",") {
+		set, ok := dstTokens[strings.ToLower(strings.TrimSpace(token))]
+		if !ok {
+			return p, fmt.Errorf("%q is not a supported daylight saving time policy "+
+				"(wall, elapsed, shift, skip, first, last, both)", token)
+		}
+		set(&p)
+	}
+	return p, nil
+}
+
+// String returns the policy in the form ParseDST parses, e.g. "wall,skip,both".
+// The default stepping has no token, so it is omitted.
+func (p DST) String() string {
+	var tokens []string
+	switch p.Stepping {
+	case WallStepping:
+		tokens = append(tokens, "wall")
+	case ElapsedStepping:
+		tokens = append(tokens, "elapsed")
+	}
+	if p.Gap == SkipGap {
+		tokens = append(tokens, "skip")
+	} else {
+		tokens = append(tokens, "shift")
+	}
+	switch p.Fold {
+	case LastFold:
+		tokens = append(tokens, "last")
+	case BothFolds:
+		tokens = append(tokens, "both")
+	default:
+		tokens = append(tokens, "first")
+	}
+	return strings.Join(tokens, ",")
+}
+
+// elapsed reports whether the period steps in elapsed time, given the
+// natural stepping of its unit.
+func (p DST) elapsed(natural bool) bool {
+	switch p.Stepping {
+	case WallStepping:
+		return false
+	case ElapsedStepping:
+		return true
+	default:
+		return natural
+	}
+}
+
+// resolve returns the instants of the wall clock c, a civil time in UTC, in
+// loc following the gap and the fold policy. It returns none for a skipped
+// nonexistent time, and two for both occurrences of an ambiguous one.
+func (p DST) resolve(c time.Time, loc *time.Location) []time.Time {
+	first, last, gap := locate(c, loc)
+	switch {
+	case gap && p.Gap == SkipGap:
+		return nil
+	case first.Equal(last) || p.Fold == FirstFold:
+		return []time.Time{first}
+	case p.Fold == LastFold:
+		return []time.Time{last}
+	default:
+		return []time.Time{first, last}
+	}
+}
+
+// Locate returns the instant of the wall clock of c in loc, whatever the
+// location of c, following the default daylight saving time policy: a
+// nonexistent time is shifted forward by the gap, and an ambiguous one is
+// the first.
+func Locate(c time.Time, loc *time.Location) time.Time {
+	t, _, _ := locate(toCivil(c), loc)
+	return t.In(loc)
+}
+
+// locate returns the first and the last instant of the wall clock c, a
+// civil time in UTC, in loc. They differ only for an ambiguous time. For a
+// nonexistent time, it reports a gap and returns the time shifted forward.
+func locate(c time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+	// The instant is within a day of c, so the offsets around it are
+	// the ones before and after a change
+	_, before := c.Add(-dstMargin).In(loc).Zone()
+	_, after := c.Add(dstMargin).In(loc).Zone()
+	a := c.Add(-time.Duration(before) * time.Second)
+	b := c.Add(-time.Duration(after) * time.Second)
+
+	validA, validB := toCivil(a.In(loc)).Equal(c), toCivil(b.In(loc)).Equal(c)
+	switch {
+	case validA && validB:
+		if b.Before(a) {
+			a, b = b, a
+		}
+		return a, b, false
+	case validA:
+		return a, a, false
+	case validB:
+		return b, b, false
+	default:
+		// Read with the offset before the gap, which moves it forward
+		return a, a, true
+	}
+}
+
+// timeline turns the wall clock times of a period, civil times in UTC, into
+// instants in its location, either on the wall clock or in elapsed time
+// from its origin.
+type timeline struct {
+	dst     DST
+	loc     *time.Location
+	elapsed bool
+	// origin is the wall clock that the elapsed time is counted from, and
+	// start its instant.
+	origin, start time.Time
+}
+
+// timeline returns the timeline of a period that starts at the wall clock
+// origin in loc.
+func (p DST) timeline(origin time.Time, loc *time.Location, elapsed bool) timeline {
+	start, _, _ := locate(origin, loc)
+	return timeline{dst: p, loc: loc, elapsed: elapsed, origin: origin, start: start}
+}
+
+// instants returns the instants of the wall clock c.
+func (tl timeline) instants(c time.Time) []time.Time {
+	if tl.elapsed {
+		return []time.Time{shift(tl.start, tl.origin, c)}
+	}
+	return tl.dst.resolve(c, tl.loc)
+}
+
+// instant returns a single instant of the wall clock c, the first one of an
+// ambiguous time or the shifted one of a nonexistent time.
+func (tl timeline) instant(c time.Time) time.Time {
+	if tl.elapsed {
+		return shift(tl.start, tl.origin, c)
+	}
+	t, _, _ := locate(c, tl.loc)
+	return t
+}
@@ -0,0 +1,243 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isoDuration matches an ISO 8601 duration such as PT1H, P1D, P1Y6M or P1W.
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?` +
+	`(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// Duration Period, described by an ISO 8601 duration (PT1H, P1D, P1Y6M, ...)
+type DurationPeriod struct {
+	// Calendar part of the duration, applied in the requested timezone
+	Years, Months, Days int
+	// Clock part of the duration, applied in absolute time
+	Clock time.Duration
+	// At is the invocation point, the start or the end of every duration.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor is a start of the durations, which start at t1 otherwise.
+	Anchor Anchor
+}
+
+func (dp DurationPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(dp.Iter(context.Background(), t1, t2, tz))
+}
+
+func (dp DurationPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	// An ISO 8601 duration has no natural alignment, so the timestamps
+	// start from t1 itself, or from the anchor.
+	start := t1.In(tz)
+
+	// Invoke at the end of every duration, which skips the first start
+	i := 0
+	if dp.At.Kind == EndInvocation {
+		i = 1
+	}
+	if !dp.Anchor.IsZero() {
+		// The durations before and after the anchor, from the one before t1
+		start = dp.Anchor.instant(tz)
+		n, err := dp.index(ctx, start, t1, tz)
+		if err != nil {
+			return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+				return nil, time.Time{}, false, err
+			})
+		}
+		i = n - 1
+	}
+
+	// Generate the periodic timestamps. Every timestamp is computed from the
+	// start, so that the calendar part never drifts (e.g. 31st + 1 month).
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		n := i
+		i++
+		floor := addTimes(addCalendar(toCivil(start), i*dp.Years, i*dp.Months, i*dp.Days),
+			i, dp.Clock).Add(-dstMargin)
+		return dp.instants(start, n, tz), floor, true, nil
+	})
+}
+
+// instants returns the instants of the nth duration from start.
+func (dp DurationPeriod) instants(start time.Time, n int, tz *time.Location) []time.Time {
+	origin := toCivil(start)
+	c := addCalendar(origin, n*dp.Years, n*dp.Months, n*dp.Days)
+
+	switch {
+	case n == 0:
+		return []time.Time{start}
+	case dp.DST.Stepping == WallStepping:
+		// Both parts on the wall clock
+		return dp.DST.resolve(addTimes(c, n, dp.Clock), tz)
+	case dp.DST.Stepping == ElapsedStepping:
+		// Both parts in elapsed time
+		return []time.Time{addTimes(shift(start, origin, c), n, dp.Clock)}
+	default:
+		// The calendar part on the wall clock, the clock part in elapsed
+		// time
+		var instants []time.Time
+		for _, t := range dp.DST.resolve(c, tz) {
+			instants = append(instants, addTimes(t, n, dp.Clock))
+		}
+		return instants
+	}
+}
+
+// index returns the last duration from start that is at or before t. It is
+// estimated from the average length of the duration, and then corrected by
+// at most maxCorrections durations.
+func (dp DurationPeriod) index(ctx context.Context, start, t time.Time,
+	tz *time.Location) (int, error) {
+	// The single instant of every duration, shifted forward in a gap
+	single := dp
+	single.DST = DST{Stepping: dp.DST.Stepping}
+	at := func(n int) time.Time { return single.instants(start, n, tz)[0] }
+
+	n := int(math.Floor(float64(t.Unix()-start.Unix()) / dp.averageSeconds()))
+	for c := 0; at(n).After(t) || !at(n+1).After(t); c++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if c == maxCorrections {
+			return 0, fmt.Errorf("the durations from the anchor are not found around %s",
+				t.UTC().Format(SUPPORTEDFORMAT))
+		}
+		if at(n).After(t) {
+			n--
+		} else {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// averageSeconds returns the average length of the duration in seconds.
+func (dp DurationPeriod) averageSeconds() float64 {
+	return float64(dp.Years)*365.2425*86400 + float64(dp.Months)*30.436875*86400 +
+		float64(dp.Days)*86400 + dp.Clock.Seconds()
+}
+
+// count returns the number of timestamps in [t1, t2) without generating
+// them, for the durations that step a fixed elapsed time. It reports false
+// when the calendar part steps on the wall clock.
+func (dp DurationPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	var step time.Duration
+	switch {
+	case dp.Years != 0 || dp.Months != 0:
+		return 0, false
+	case dp.DST.Stepping == ElapsedStepping:
+		step = time.Duration(dp.Days)*24*time.Hour + dp.Clock
+	case dp.Days == 0 && dp.DST.Stepping == DefaultStepping:
+		step = dp.Clock
+	default:
+		return 0, false
+	}
+	if !t1.Before(t2) {
+		return 0, true
+	}
+
+	if !dp.Anchor.IsZero() {
+		// The timestamps are anchor + n*step, before and after the anchor
+		a := dp.Anchor.instant(tz)
+		before := func(t time.Time) int { return -steps(t, a, step) }
+		return before(t2) - before(t1), true
+	}
+
+	// The timestamps are t1 + n*step, from the first or the second one
+	n := -steps(t2, t1, step)
+	if dp.At.Kind == EndInvocation {
+		n--
+	}
+	return n, true
+}
+
+func (dp DurationPeriod) withInvocation(at Invocation) (Period, error) {
+	if at.Kind == OffsetInvocation {
+		return nil, fmt.Errorf("an ISO 8601 duration is only invoked at its start or end")
+	}
+	dp.At = at
+	return dp, nil
+}
+
+func (dp DurationPeriod) withDST(dst DST) Period {
+	dp.DST = dst
+	return dp
+}
+
+func (dp DurationPeriod) withAnchor(a Anchor) (Period, error) {
+	dp.Anchor = a
+	return dp, nil
+}
+
+// addCalendar adds the calendar part of a duration to the wall clock t,
+// keeping its time of the day. Unlike time.AddDate, the day of the month is
+// clamped to the end of a shorter month (31 Jan + 1 month = 28 Feb).
+func addCalendar(t time.Time, years, months, days int) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+
+	// The last day of the target month
+	last := time.Date(y+years, m+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if d > last {
+		d = last
+	}
+
+	return time.Date(y+years, m+time.Month(months), d+days, hh, mm, ss,
+		t.Nanosecond(), t.Location())
+}
+
+// parseDuration parses an ISO 8601 duration such as PT1H, P1D or P1Y6M.
+func parseDuration(s string) (Period, error) {
+	m := isoDuration.FindStringSubmatch(s)
+	if m == nil || strings.HasSuffix(s, "T") {
+		return nil, fmt.Errorf("%q is not a valid ISO 8601 duration", s)
+	}
+
+	var n [7]int
+	for i, v := range m[1:7] {
+		if v == "" {
+			continue
+		}
+		x, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid component %q: %v", s, v, err)
+		}
+		n[i] = x
+	}
+
+	var secs float64
+	if m[7] != "" {
+		x, err := strconv.ParseFloat(strings.Replace(m[7], ",", ".", 1), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q has invalid seconds %q: %v", s, m[7], err)
+		}
+		secs = x
+	}
+
+	// The longest calendar part, with the clock part, fits in a time.Duration
+	longest := (float64(n[0])*366+float64(n[1])*31+float64(7*n[2]+n[3]))*86400 +
+		float64(n[4])*3600 + float64(n[5])*60 + secs
+	if longest > time.Duration(math.MaxInt64).Seconds() {
+		return nil, fmt.Errorf("%q should be at most about 292 years long", s)
+	}
+
+	dp := DurationPeriod{
+		Years:  n[0],
+		Months: n[1],
+		Days:   7*n[2] + n[3],
+		Clock: time.Duration(n[4])*time.Hour + time.Duration(n[5])*time.Minute +
+			time.Duration(secs*float64(time.Second)),
+	}
+	if dp.Years == 0 && dp.Months == 0 && dp.Days == 0 && dp.Clock <= 0 {
+		return nil, fmt.Errorf("%q should be a positive duration", s)
+	}
+
+	return dp, nil
+}
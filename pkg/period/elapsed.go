@@ -0,0 +1,40 @@
+package period
+
+import (
+	"math/big"
+	"time"
+)
+
+// The elapsed time between two timestamps of the supported years (1 to 9999)
+// may not fit in a time.Duration, which saturates after about 292 years, so
+// the arithmetic across the whole range counts seconds and nanoseconds.
+
+// addSeconds adds n seconds to the time t.
+func addSeconds(t time.Time, n int64) time.Time {
+	return time.Unix(t.Unix()+n, int64(t.Nanosecond())).In(t.Location())
+}
+
+// addTimes adds n times the duration d to the time t.
+func addTimes(t time.Time, n int, d time.Duration) time.Time {
+	sec, nsec := int64(d/time.Second), int64(d%time.Second)
+	// n*nsec may overflow, unlike the nanoseconds of its billions and of
+	// the rest
+	billions, rest := int64(n)/1e9, int64(n)%1e9
+	secs := int64(n)*sec + billions*nsec + rest*nsec/1e9
+	return time.Unix(t.Unix()+secs, int64(t.Nanosecond())+rest*nsec%1e9).In(t.Location())
+}
+
+// shift returns the time t moved by the elapsed time from from to to.
+func shift(t, from, to time.Time) time.Time {
+	return time.Unix(t.Unix()+to.Unix()-from.Unix(),
+		int64(t.Nanosecond()+to.Nanosecond()-from.Nanosecond())).In(t.Location())
+}
+
+// steps returns the number of whole steps from from to to, rounded down, so
+// that it is negative when to is before from.
+func steps(from, to time.Time, step time.Duration) int {
+	d := new(big.Int).Mul(big.NewInt(to.Unix()-from.Unix()), big.NewInt(int64(time.Second)))
+	d.Add(d, big.NewInt(int64(to.Nanosecond()-from.Nanosecond())))
+	// The Euclidean division of a positive step rounds down
+	return int(d.Div(d, big.NewInt(int64(step))).Int64())
+}
@@ -0,0 +1,420 @@
+package period
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InvocationKind tells where inside a period a task is invoked.
+type InvocationKind int
+
+// Constants for all supported invocation kinds
+const (
+	// DefaultInvocation uses the invocation point of the period, which is
+	// the start for hours and days, and the end for the longer periods.
+	DefaultInvocation InvocationKind = iota
+	// StartInvocation invokes the task at the start of the period.
+	StartInvocation
+	// EndInvocation invokes the task at the end of the period, which is
+	// the start of the next one.
+	EndInvocation
+	// OffsetInvocation invokes the task at an offset from the start of
+	// the period, e.g. day 15 at 09:30 or minute 5 of every hour.
+	OffsetInvocation
+)
+
+// Invocation is the point inside a period where a task is invoked.
+type Invocation struct {
+	Kind InvocationKind
+	// Month is the month inside a period of months, quarters or years,
+	// starting from 1. A negative month counts from the end of the period.
+	Month int
+	// Day is the day inside the month, when given, or inside the period,
+	// starting from 1. A negative day counts from the end. It is clamped
+	// to the length of a shorter month or period.
+	Day int
+	// Nth selects the nth Weekday inside the month, when given, or inside
+	// the period, e.g. the 2nd Tuesday. A negative Nth counts from the end,
+	// e.g. the last Friday. Periods without such a weekday are skipped.
+	Nth     int
+	Weekday time.Weekday
+	// BusinessDay is the nth business day (Monday to Friday) inside the
+	// month, when given, or inside the period. A negative BusinessDay counts
+	// from the end, e.g. the last business day.
+	BusinessDay int
+	// Clock is the time of the day for periods of days or longer, and the
+	// offset from the start of the period for minutes and hours.
+	Clock time.Duration
+}
+
+// ordinals maps the ordinal words of an invocation point to their number.
+var ordinals = map[string]int{
+	"first": 1, "1st": 1,
+	"second": 2, "2nd": 2,
+	"third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4,
+	"fifth": 5, "5th": 5,
+	"last": -1,
+}
+
+// weekdayNames maps the names of the weekdays to their value.
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ParseInvocation parses an invocation point such as start, end,
+// "day 15 at 09:30", "month -1 day -1 at 18:00", "minute 5 of every hour",
+// "2nd tuesday at 10:00", "last friday of the month" or "last business day".
+// An empty string is the default invocation point of the period.
+func ParseInvocation(s string) (Invocation, error) {
+	var at Invocation
+
+	tokens := strings.Fields(strings.ToLower(s))
+	switch {
+	case len(tokens) == 0:
+		return at, nil
+	case len(tokens) == 1 && tokens[0] == "start":
+		return Invocation{Kind: StartInvocation}, nil
+	case len(tokens) == 1 && tokens[0] == "end":
+		return Invocation{Kind: EndInvocation}, nil
+	}
+
+	at.Kind = OffsetInvocation
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] == "the" {
+			continue
+		}
+		// The trailing "of every <unit>" or "of the <unit>" is only descriptive
+		if tokens[i] == "of" && i+2 == len(tokens)-1 &&
+			(tokens[i+1] == "every" || tokens[i+1] == "the") {
+			break
+		}
+
+		// An ordinal followed by a weekday or a business day
+		if n, ok := ordinals[tokens[i]]; ok && i+1 < len(tokens) {
+			next := tokens[i+1]
+			if wd, ok := weekdayNames[next]; ok {
+				at.Nth, at.Weekday = n, wd
+				i++
+				continue
+			}
+			if next == "weekday" {
+				at.BusinessDay = n
+				i++
+				continue
+			}
+			if next == "business" && i+2 < len(tokens) && tokens[i+2] == "day" {
+				at.BusinessDay = n
+				i += 2
+				continue
+			}
+			return at, fmt.Errorf("%q should have a weekday or a business day after %s",
+				s, tokens[i])
+		}
+
+		if i+1 >= len(tokens) {
+			return at, fmt.Errorf("%q has no value for %s", s, tokens[i])
+		}
+
+		key, value := tokens[i], tokens[i+1]
+		i++
+		if key == "at" {
+			clock, err := parseClock(value)
+			if err != nil {
+				return at, fmt.Errorf("%q: %v", s, err)
+			}
+			at.Clock += clock
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return at, fmt.Errorf("%q has an invalid %s %q", s, key, value)
+		}
+		switch key {
+		case "month":
+			at.Month = n
+		case "day":
+			at.Day = n
+		case "hour":
+			at.Clock += time.Duration(n) * time.Hour
+		case "minute":
+			at.Clock += time.Duration(n) * time.Minute
+		case "second":
+			at.Clock += time.Duration(n) * time.Second
+		default:
+			return at, fmt.Errorf("%q has an unsupported part %q", s, key)
+		}
+		if n == 0 && (key == "month" || key == "day") {
+			return at, fmt.Errorf("%q should have a non zero %s", s, key)
+		}
+		if n < 0 && key != "month" && key != "day" {
+			return at, fmt.Errorf("%q should have a positive %s", s, key)
+		}
+	}
+
+	return at, nil
+}
+
+// ordinalNames are the ordinal words of the numbers of an invocation point.
+var ordinalNames = map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth",
+	5: "fifth", -1: "last"}
+
+// String returns the invocation point in the form ParseInvocation parses,
+// e.g. "month -1 day -1 at 18:00" or "second tuesday at 10:00". It is empty
+// for the default invocation point.
+func (at Invocation) String() string {
+	switch at.Kind {
+	case StartInvocation:
+		return "start"
+	case EndInvocation:
+		return "end"
+	case OffsetInvocation:
+	default:
+		return ""
+	}
+
+	var tokens []string
+	if at.Month != 0 {
+		tokens = append(tokens, "month", strconv.Itoa(at.Month))
+	}
+	if at.Day != 0 {
+		tokens = append(tokens, "day", strconv.Itoa(at.Day))
+	}
+	if at.Nth != 0 {
+		tokens = append(tokens, ordinalNames[at.Nth], strings.ToLower(at.Weekday.String()))
+	}
+	if at.BusinessDay != 0 {
+		tokens = append(tokens, ordinalNames[at.BusinessDay], "business", "day")
+	}
+	// A clock past a day has its hours apart, since a time of the day is
+	// before 24:00
+	clock := at.Clock
+	if clock >= 24*time.Hour {
+		tokens = append(tokens, "hour", strconv.Itoa(int(clock/time.Hour)))
+		clock %= time.Hour
+	}
+	switch {
+	case clock%time.Minute != 0:
+		tokens = append(tokens, "at", fmt.Sprintf("%02d:%02d:%02d",
+			clock/time.Hour, clock%time.Hour/time.Minute, clock%time.Minute/time.Second))
+	case clock != 0 || len(tokens) == 0:
+		tokens = append(tokens, "at", fmt.Sprintf("%02d:%02d",
+			clock/time.Hour, clock%time.Hour/time.Minute))
+	}
+	return strings.Join(tokens, " ")
+}
+
+// parseClock parses a time of the day such as 09:30 or 09:30:15.
+func parseClock(s string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a valid time of the day (HH:MM[:SS])", s)
+}
+
+// or returns the invocation point, or the given kind when it is the default.
+func (at Invocation) or(kind InvocationKind) Invocation {
+	if at.Kind == DefaultInvocation {
+		at.Kind = kind
+	}
+	return at
+}
+
+// validate checks that the invocation point fits inside n units.
+func (at Invocation) validate(unit Unit, n int) error {
+	if at.Kind != OffsetInvocation {
+		return nil
+	}
+
+	if at.Month != 0 {
+		months := n * unit.months()
+		if months == 0 {
+			return fmt.Errorf("a month is only valid for periods of months, quarters or years")
+		}
+		if at.Month > months || at.Month < -months {
+			return fmt.Errorf("month %d is outside of a period of %d months", at.Month, months)
+		}
+	}
+
+	if at.Day != 0 && unit < Day {
+		return fmt.Errorf("a day is only valid for periods of days or longer")
+	}
+	// The days and the weeks have a fixed number of days, which a day should
+	// not be clamped to
+	if days := map[Unit]int{Day: n, Week: 7 * n}[unit]; days > 0 &&
+		(at.Day > days || at.Day < -days) {
+		return fmt.Errorf("day %d is outside of a period of %d days", at.Day, days)
+	}
+
+	if at.Nth != 0 && unit < Month {
+		return fmt.Errorf("a weekday is only valid for periods of months, quarters or years")
+	}
+
+	if at.BusinessDay != 0 && unit < Week {
+		return fmt.Errorf("a business day is only valid for periods of weeks or longer")
+	}
+
+	if (at.Day != 0 && at.Nth != 0) || (at.Day != 0 && at.BusinessDay != 0) ||
+		(at.Nth != 0 && at.BusinessDay != 0) {
+		return fmt.Errorf("only one of a day, a weekday or a business day should be given")
+	}
+
+	if unit >= Day {
+		if at.Clock >= 24*time.Hour {
+			return fmt.Errorf("the time of the day should be before 24:00")
+		}
+	} else if length := time.Duration(n) * unit.duration(); at.Clock >= length {
+		return fmt.Errorf("the offset %s is outside of a period of %s", at.Clock, length)
+	}
+
+	return nil
+}
+
+// apply returns the wall clock of the invocation point of the period
+// [start, end), whose bounds are wall clocks (civil times in UTC) at the
+// midnight for periods of days or longer, unless they are anchored. The time
+// of the day then applies to the day of the start, so the point may be
+// before the start, but not before its midnight. It reports false when the
+// period has no such invocation point, e.g. the 5th Friday.
+func (at Invocation) apply(start, end time.Time, unit Unit) (time.Time, bool) {
+	switch at.Kind {
+	case EndInvocation:
+		return end, true
+	case OffsetInvocation:
+	default:
+		return start, true
+	}
+
+	if unit < Day {
+		return start.Add(at.Clock), true
+	}
+
+	// Move to the requested month and day, and then to the time of the day
+	base, span := start, end
+	if at.Month != 0 {
+		m := at.Month - 1
+		if at.Month < 0 {
+			m = monthsBetween(start, end) + at.Month
+		}
+		base, span = start.AddDate(0, m, 0), start.AddDate(0, m+1, 0)
+	}
+
+	switch {
+	case at.Nth != 0:
+		d, ok := nthWeekday(base, span, at.Weekday, at.Nth)
+		if !ok {
+			return time.Time{}, false
+		}
+		base = d
+	case at.BusinessDay != 0:
+		d, ok := nthBusinessDay(base, span, at.BusinessDay)
+		if !ok {
+			return time.Time{}, false
+		}
+		base = d
+	case at.Day != 0:
+		days := daysBetween(base, span)
+		d := at.Day - 1
+		if at.Day < 0 {
+			d = days + at.Day
+		}
+		if d < 0 {
+			d = 0
+		}
+		if d >= days {
+			d = days - 1
+		}
+		base = base.AddDate(0, 0, d)
+	}
+
+	y, m, d := base.Date()
+	return time.Date(y, m, d, int(at.Clock/time.Hour), int(at.Clock/time.Minute)%60,
+		int(at.Clock/time.Second)%60, 0, base.Location()), true
+}
+
+// nthWeekday returns the nth weekday of the days [from, to), counting from
+// the end for a negative n.
+func nthWeekday(from, to time.Time, wd time.Weekday, n int) (time.Time, bool) {
+	days := daysBetween(from, to)
+
+	var d int
+	if n > 0 {
+		first := (int(wd) - int(from.Weekday()) + 7) % 7
+		d = first + (n-1)*7
+	} else {
+		last := from.AddDate(0, 0, days-1).Weekday()
+		d = days - 1 - (int(last)-int(wd)+7)%7 + (n+1)*7
+	}
+
+	if d < 0 || d >= days {
+		return time.Time{}, false
+	}
+	return from.AddDate(0, 0, d), true
+}
+
+// nthBusinessDay returns the nth business day (Monday to Friday) of the days
+// [from, to), counting from the end for a negative n.
+func nthBusinessDay(from, to time.Time, n int) (time.Time, bool) {
+	days := daysBetween(from, to)
+
+	d, step := 0, 1
+	if n < 0 {
+		d, step, n = days-1, -1, -n
+	}
+	for ; d >= 0 && d < days; d += step {
+		t := from.AddDate(0, 0, d)
+		if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+			continue
+		}
+		if n--; n == 0 {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// months returns the number of months of the unit, or zero for the units
+// shorter than a month.
+func (u Unit) months() int {
+	switch u {
+	case Month:
+		return 1
+	case Quarter:
+		return 3
+	case Year:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// duration returns the length of the units shorter than a day.
+func (u Unit) duration() time.Duration {
+	if u == Minute {
+		return time.Minute
+	}
+	return time.Hour
+}
+
+// monthsBetween returns the number of months between two starts of months.
+func monthsBetween(t1, t2 time.Time) int {
+	return (t2.Year()-t1.Year())*12 + int(t2.Month()) - int(t1.Month())
+}
+
+// daysBetween returns the number of calendar days between two local dates.
+func daysBetween(t1, t2 time.Time) int {
+	return int((toCivil(t2).Unix() - toCivil(t1).Unix()) / 86400)
+}
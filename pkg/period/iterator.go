@@ -0,0 +1,131 @@
+package period
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Iterator yields the matching timestamps of a period lazily, in order and
+// without duplicates:
+//
+//	it := p.Iter(ctx, t1, t2, tz)
+//	for it.Next() {
+//		t := it.Time()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator interface {
+	// Next advances to the next timestamp. It reports false at the end of
+	// the range, or when the iteration stopped with an error.
+	Next() bool
+	// Time returns the current timestamp, in the requested timezone.
+	Time() time.Time
+	// Err returns the error that stopped the iteration, such as the
+	// cancellation of its context.
+	Err() error
+}
+
+// generator produces the candidate instants of a period in batches. It
+// returns the instants of a batch, unordered and possibly out of the range,
+// a floor that the instants of the next batches are at or after, and false
+// after the last batch.
+type generator func() (instants []time.Time, floor time.Time, more bool, err error)
+
+// iterator orders the instants of a generator. Since every instant is at or
+// after the floor of the previous batch, the buffered instants before the
+// floor are final and yielded in order.
+type iterator struct {
+	ctx    context.Context
+	t1, t2 time.Time
+	loc    *time.Location
+	next   generator
+
+	// pending holds the sorted instants in the range that are not yielded
+	pending []time.Time
+	floor   time.Time
+	done    bool
+
+	current time.Time
+	started bool
+	err     error
+}
+
+// newIterator returns an iterator of the instants of next in [t1, t2),
+// which yields them in loc.
+func newIterator(ctx context.Context, t1, t2 time.Time, loc *time.Location,
+	next generator) *iterator {
+	return &iterator{ctx: ctx, t1: t1, t2: t2, loc: loc, next: next}
+}
+
+func (it *iterator) Next() bool {
+	for it.err == nil {
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			break
+		}
+
+		// Yield the first pending instant, unless a later batch may precede it
+		if len(it.pending) > 0 && (it.done || it.pending[0].Before(it.floor)) {
+			t := it.pending[0]
+			it.pending = it.pending[1:]
+			if it.started && !t.After(it.current) {
+				continue
+			}
+			it.current, it.started = t.In(it.loc), true
+			return true
+		}
+		if it.done {
+			return false
+		}
+
+		instants, floor, more, err := it.next()
+		if err != nil {
+			it.err = err
+			break
+		}
+		for _, t := range instants {
+			if !t.Before(it.t1) && t.Before(it.t2) {
+				it.insert(t)
+			}
+		}
+		// No later batch can be in the range
+		it.floor = floor
+		it.done = !more || !floor.Before(it.t2)
+	}
+	return false
+}
+
+// insert adds t to the sorted pending instants.
+func (it *iterator) insert(t time.Time) {
+	i := sort.Search(len(it.pending), func(i int) bool { return t.Before(it.pending[i]) })
+	it.pending = append(it.pending, time.Time{})
+	copy(it.pending[i+1:], it.pending[i:])
+	it.pending[i] = t
+}
+
+func (it *iterator) Time() time.Time {
+	return it.current
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Collect returns all the timestamps of an iterator.
+func Collect(it Iterator) ([]time.Time, error) {
+	var list []time.Time
+	for it.Next() {
+		list = append(list, it.Time())
+	}
+	return list, it.Err()
+}
+
+// timestamps returns the formatted timestamps of an iterator, the thin
+// adapter of GetMatchingTimestamps over Iter.
+func timestamps(it Iterator) []string {
+	list, _ := Collect(it)
+	return Format(list)
+}
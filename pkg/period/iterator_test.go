@@ -0,0 +1,125 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Iter(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20220101T000000Z")
+
+	for _, s := range []string{ONEHOUR, ONEDAY, ONEWEEK, ONEMONTH, ONEQUARTER, ONEYEAR,
+		"15m", "P1DT12H", "0 9 * * 1-5", "FREQ=MONTHLY;BYDAY=-1FR"} {
+		t.Run(s, func(t *testing.T) {
+			p, err := ParsePeriod(s)
+			assert.NoError(t, err)
+
+			list, err := Collect(p.Iter(context.Background(), t1, t2, tz))
+			assert.NoError(t, err)
+
+			expected := p.GetMatchingTimestamps(t1, t2, tz)
+			if assert.Len(t, list, len(expected)) {
+				for i := range list {
+					assert.Equal(t, expected[i], list[i].UTC().Format(SUPPORTEDFORMAT))
+				}
+			}
+		})
+	}
+}
+
+func TestPeriod_IterIsLazy(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2 := t1.AddDate(1000, 0, 0)
+
+	// A thousand years of hours are never generated
+	it := NewPeriod(ONEHOUR).Iter(context.Background(), t1, t2, tz)
+	for i := 0; i < 3; i++ {
+		assert.True(t, it.Next())
+		assert.Equal(t, t1.Add(time.Duration(i)*time.Hour), it.Time().UTC())
+	}
+	assert.NoError(t, it.Err())
+}
+
+func TestPeriod_IterCancel(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2 := t1.AddDate(1000, 0, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p, _ := ParsePeriod("1m")
+	it := p.Iter(ctx, t1, t2, tz)
+	assert.True(t, it.Next())
+
+	cancel()
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+}
+
+func TestPeriod_IterDeadline(t *testing.T) {
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "00010101T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "99991231T235959Z")
+
+	// The days at midnight never match at noon, so every generation searches
+	// the whole range unless the deadline stops it
+	p, err := ParsePeriod(`{"intersect": [{"period": "1d"}, {"period": "0 12 * * *"}]}`)
+	assert.NoError(t, err)
+
+	generations := map[string]func(ctx context.Context) error{
+		"Iter": func(ctx context.Context) error {
+			_, err := Collect(p.Iter(ctx, t1, t2, time.UTC))
+			return err
+		},
+		"Count": func(ctx context.Context) error {
+			_, err := ClosedOpen.Count(ctx, p, t1, t2, time.UTC)
+			return err
+		},
+		"Exceeds": func(ctx context.Context) error {
+			_, err := ClosedOpen.Exceeds(ctx, p, t1, t2, time.UTC, 10)
+			return err
+		},
+		"Resume": func(ctx context.Context) error {
+			_, err := Collect(ClosedOpen.Resume(ctx, p, t1, t2, t1.AddDate(1, 0, 0), time.UTC))
+			return err
+		},
+		"NextN": func(ctx context.Context) error {
+			_, err := NextN(ctx, p, t1, 1, time.UTC)
+			return err
+		},
+		"Prev": func(ctx context.Context) error {
+			_, err := Prev(ctx, p, t2, time.UTC)
+			return err
+		},
+	}
+	for name, generate := range generations {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := generate(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Less(t, time.Since(start), time.Second, "Expected the generation to stop early")
+		})
+	}
+}
+
+func TestPeriod_IterOrder(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20211030T230000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211031T030000Z")
+
+	// Both occurrences of the fold are yielded in order among the others
+	p, _ := ParsePeriodWithOptions("*/30 * * * *", Options{DST: DST{Fold: BothFolds}})
+	list, err := Collect(p.Iter(context.Background(), t1, t2, tz))
+	assert.NoError(t, err)
+	for i := 1; i < len(list); i++ {
+		assert.Equal(t, 30*time.Minute, list[i].Sub(list[i-1]))
+	}
+	assert.Len(t, list, 8)
+}
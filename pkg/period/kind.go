@@ -0,0 +1,54 @@
+package period
+
+import "strconv"
+
+// Constants for the kinds of the periods that are not a multiple of a unit
+const (
+	KindDuration = "duration"
+	KindRRule    = "rrule"
+	KindCron     = "cron"
+	KindSchedule = "schedule"
+)
+
+// kinded is implemented by the periods that know their kind.
+type kinded interface {
+	kind() string
+}
+
+// Kind returns the kind of the period p, which groups the periods of the same
+// form and cost, whatever their text: the multiple of a unit (e.g. 1h or 15m),
+// KindDuration, KindRRule, KindCron or KindSchedule for the composed periods.
+// It is empty for the other registered periods.
+func Kind(p Period) string {
+	if k, ok := p.(kinded); ok {
+		return k.kind()
+	}
+	return ""
+}
+
+func (OneHourPeriod) kind() string    { return ONEHOUR }
+func (OneDayPeriod) kind() string     { return ONEDAY }
+func (OneWeekPeriod) kind() string    { return ONEWEEK }
+func (OneMonthPeriod) kind() string   { return ONEMONTH }
+func (OneQuarterPeriod) kind() string { return ONEQUARTER }
+func (OneYearPeriod) kind() string    { return ONEYEAR }
+
+func (mp MultiplePeriod) kind() string {
+	for suffix, unit := range unitSuffixes {
+		if unit == mp.Unit {
+			return strconv.Itoa(mp.N) + suffix
+		}
+	}
+	return ""
+}
+
+func (DurationPeriod) kind() string  { return KindDuration }
+func (RRulePeriod) kind() string     { return KindRRule }
+func (CronPeriod) kind() string      { return KindCron }
+func (UnionPeriod) kind() string     { return KindSchedule }
+func (IntersectPeriod) kind() string { return KindSchedule }
+func (ExceptPeriod) kind() string    { return KindSchedule }
+func (WindowPeriod) kind() string    { return KindSchedule }
+
+// The adjusted timestamps are the ones of the period
+func (ap AdjustedPeriod) kind() string { return Kind(ap.Period) }
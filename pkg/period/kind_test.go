@@ -0,0 +1,34 @@
+package period
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Kind(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected string
+	}{
+		{"1h", ONEHOUR},
+		{"1q", ONEQUARTER},
+		{"15m", "15m"},
+		{"3mo", "3mo"},
+		{"PT1H", KindDuration},
+		{"FREQ=DAILY;INTERVAL=2", KindRRule},
+		{"DTSTART:20210101T090000Z\nRRULE:FREQ=WEEKLY", KindRRule},
+		{"0 9 * * 1-5", KindCron},
+		{`{"union":[{"period":"1d"},{"period":"0 12 * * *"}]}`, KindSchedule},
+		// A schedule of a single period is that period
+		{`{"period":"6h"}`, "6h"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeriod(tt.s)
+		assert.NoError(t, err, tt.s)
+		assert.Equal(t, tt.expected, Kind(p), tt.s)
+	}
+
+	// The business days are the ones of the period
+	assert.Equal(t, KindCron, Kind(AdjustedPeriod{Period: CronPeriod{}}))
+}
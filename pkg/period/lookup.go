@@ -0,0 +1,213 @@
+package period
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrNoMatch is returned when a period has no matching timestamp within
+// lookupYears of the requested time.
+var ErrNoMatch = errors.New("no matching timestamp")
+
+// ErrNoPhase is returned when the timestamps of a period would take their
+// phase from the requested time, e.g. an ISO 8601 duration, which would
+// start at it and match any time, or 2d without an anchor.
+var ErrNoPhase = errors.New("the period has no phase of its own, " +
+	"it needs an anchor or a DTSTART")
+
+// lookupYears bounds the search of the next and the previous timestamps, so
+// that periods that never match again terminate.
+const lookupYears = 100
+
+// lookupWindow is the first window that Prev searches before the requested
+// time. The window doubles until a timestamp is found.
+const lookupWindow = 24 * time.Hour
+
+// Next returns the first matching timestamp of p after the time after, in the
+// requested timezone.
+//
+// The ISO 8601 durations, the recurrence rules without a DTSTART that depend
+// on their start, and the multiples that do not tile their unit (e.g. 2d or
+// 7h) have no phase unless they are anchored, and return ErrNoPhase.
+func Next(ctx context.Context, p Period, after time.Time, tz *time.Location) (time.Time, error) {
+	list, err := NextN(ctx, p, after, 1, tz)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return list[0], nil
+}
+
+// NextN returns the first n matching timestamps of p after the time after, in
+// the requested timezone. It returns ErrNoMatch with the timestamps found, if
+// there are fewer than n.
+func NextN(ctx context.Context, p Period, after time.Time, n int, tz *time.Location) ([]time.Time, error) {
+	if !phased(p) {
+		return nil, ErrNoPhase
+	}
+	it := p.Iter(ctx, after, after.AddDate(lookupYears, 0, 0), tz)
+
+	var list []time.Time
+	for len(list) < n && it.Next() {
+		// A timestamp at the requested time is not after it
+		if t := it.Time(); t.After(after) {
+			list = append(list, t)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return list, err
+	}
+	if len(list) < n {
+		return list, ErrNoMatch
+	}
+	return list, nil
+}
+
+// Prev returns the last matching timestamp of p before the time before, in the
+// requested timezone.
+//
+// Since the periods are generated forward, it searches the windows before
+// the requested time, doubling their length, until one has a timestamp. Like
+// Next, it returns ErrNoPhase for a period without a phase.
+func Prev(ctx context.Context, p Period, before time.Time, tz *time.Location) (time.Time, error) {
+	if !phased(p) {
+		return time.Time{}, ErrNoPhase
+	}
+	limit := before.AddDate(-lookupYears, 0, 0)
+	end := before
+	for window := lookupWindow; end.After(limit); window *= 2 {
+		start := end.Add(-window)
+		if start.Before(limit) {
+			start = limit
+		}
+
+		var last time.Time
+		var found bool
+		it := p.Iter(ctx, start, end, tz)
+		for it.Next() {
+			last, found = it.Time(), true
+		}
+		if err := it.Err(); err != nil {
+			return time.Time{}, err
+		}
+		if found {
+			return last, nil
+		}
+		end = start
+	}
+	return time.Time{}, ErrNoMatch
+}
+
+// phaser is implemented by the periods that may take their phase from the
+// start of the requested range, rather than from the calendar.
+type phaser interface {
+	phased() bool
+}
+
+// phased reports whether the timestamps of p are the same from any start of
+// the range, so that the timestamps next to a time do not depend on it.
+func phased(p Period) bool {
+	if pp, ok := p.(phaser); ok {
+		return pp.phased()
+	}
+	return true
+}
+
+func (dp DurationPeriod) phased() bool {
+	return !dp.Anchor.IsZero()
+}
+
+func (rp RRulePeriod) phased() bool {
+	if !rp.dtstart.IsZero() || !rp.Anchor.IsZero() {
+		return true
+	}
+	// The rule starts at the local midnight of the range, which matters to
+	// an interval, to a count, and to the parts that default to the start,
+	// e.g. the weekday of a weekly rule
+	if rp.interval > 1 || rp.count > 0 {
+		return false
+	}
+	monday := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2002, 2, 2, 0, 0, 0, 0, time.UTC)
+	return reflect.DeepEqual(rp.withDefaults(monday), rp.withDefaults(saturday))
+}
+
+func (mp MultiplePeriod) phased() bool {
+	if !mp.Anchor.IsZero() {
+		return true
+	}
+	// Without an anchor, the periods are aligned to the unit that contains
+	// t1 (see align), which only fixes their phase when the multiples tile
+	// it. The minutes and the hours in elapsed time restart from the local
+	// midnight of t1, so a step also has to tile the hour that a daylight
+	// saving time change moves the clock by.
+	elapsed := mp.DST.elapsed(mp.Unit < Day)
+	switch mp.Unit {
+	case Minute, Hour:
+		if elapsed {
+			return time.Hour%mp.step() == 0
+		}
+		return 24*time.Hour%mp.step() == 0
+	case Month:
+		return !elapsed && 12%mp.N == 0
+	case Quarter:
+		return !elapsed && 4%mp.N == 0
+	default:
+		return !elapsed && mp.N == 1
+	}
+}
+
+func (ohp OneHourPeriod) phased() bool {
+	return ohp.multiple().phased()
+}
+
+func (odp OneDayPeriod) phased() bool {
+	return odp.multiple().phased()
+}
+
+func (owp OneWeekPeriod) phased() bool {
+	return owp.multiple().phased()
+}
+
+func (omp OneMonthPeriod) phased() bool {
+	return omp.multiple().phased()
+}
+
+func (oqp OneQuarterPeriod) phased() bool {
+	return oqp.multiple().phased()
+}
+
+func (oyp OneYearPeriod) phased() bool {
+	return oyp.multiple().phased()
+}
+
+func (ap AdjustedPeriod) phased() bool {
+	return phased(ap.Period)
+}
+
+func (up UnionPeriod) phased() bool {
+	return phasedAll(up.Periods)
+}
+
+func (ip IntersectPeriod) phased() bool {
+	return phasedAll(ip.Periods)
+}
+
+func (ep ExceptPeriod) phased() bool {
+	return phasedAll([]Period{ep.Period, ep.Excluded})
+}
+
+func (wp WindowPeriod) phased() bool {
+	return phased(wp.Period)
+}
+
+// phasedAll reports whether all the periods are phased.
+func phasedAll(periods []Period) bool {
+	for _, p := range periods {
+		if !phased(p) {
+			return false
+		}
+	}
+	return true
+}
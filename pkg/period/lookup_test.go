@@ -0,0 +1,255 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Next(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		after    string
+		n        int
+		expected []string
+	}{
+		{
+			name:     "Next hour",
+			period:   ONEHOUR,
+			after:    "20210714T204603Z",
+			n:        1,
+			expected: []string{"20210714T210000Z"},
+		},
+		{
+			name:     "A timestamp at the requested time is not next",
+			period:   ONEHOUR,
+			after:    "20210714T210000Z",
+			n:        1,
+			expected: []string{"20210714T220000Z"},
+		},
+		{
+			name:     "Next months",
+			period:   ONEMONTH,
+			after:    "20210214T204603Z",
+			n:        3,
+			expected: []string{"20210228T220000Z", "20210331T210000Z", "20210430T210000Z"},
+		},
+		{
+			name:     "Next cron fire times",
+			period:   "0 9 * * 1-5",
+			after:    "20211029T060000Z",
+			n:        2,
+			expected: []string{"20211101T070000Z", "20211102T070000Z"},
+		},
+		{
+			name:     "Next years",
+			period:   ONEYEAR,
+			after:    "20210101T000000Z",
+			n:        2,
+			expected: []string{"20211231T220000Z", "20221231T220000Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, _ := time.Parse(SUPPORTEDFORMAT, tt.after)
+			p, err := ParsePeriod(tt.period)
+			assert.NoError(t, err)
+
+			list, err := NextN(context.Background(), p, after, tt.n, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, Format(list))
+
+			next, err := Next(context.Background(), p, after, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected[0], next.UTC().Format(SUPPORTEDFORMAT))
+		})
+	}
+}
+
+func TestPeriod_Prev(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		before   string
+		expected string
+	}{
+		{
+			name:     "Previous hour",
+			period:   ONEHOUR,
+			before:   "20210714T204603Z",
+			expected: "20210714T200000Z",
+		},
+		{
+			name:     "A timestamp at the requested time is not previous",
+			period:   ONEHOUR,
+			before:   "20210714T200000Z",
+			expected: "20210714T190000Z",
+		},
+		{
+			name:     "Previous year",
+			period:   ONEYEAR,
+			before:   "20211115T123456Z",
+			expected: "20201231T220000Z",
+		},
+		{
+			name:     "Previous cron fire time",
+			period:   "0 9 * * 1-5",
+			before:   "20211101T060000Z",
+			expected: "20211029T060000Z",
+		},
+		{
+			name:     "Previous occurrence of a rule with a DTSTART",
+			period:   "DTSTART:20200101T090000Z\nRRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1",
+			before:   "20211115T000000Z",
+			expected: "20210301T090000Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := time.Parse(SUPPORTEDFORMAT, tt.before)
+			p, err := ParsePeriod(tt.period)
+			assert.NoError(t, err)
+
+			prev, err := Prev(context.Background(), p, before, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, prev.UTC().Format(SUPPORTEDFORMAT))
+		})
+	}
+}
+
+func TestPeriod_NoMatch(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	now, _ := time.Parse(SUPPORTEDFORMAT, "20210714T204603Z")
+
+	// The rule ended before now, and starts after the previous century
+	p, err := ParsePeriod("DTSTART:20200101T090000Z\nRRULE:FREQ=DAILY;COUNT=3")
+	assert.NoError(t, err)
+
+	_, err = Next(context.Background(), p, now, tz)
+	assert.ErrorIs(t, err, ErrNoMatch)
+
+	list, err := NextN(context.Background(), NewPeriod(ONEYEAR), now, 200, tz)
+	assert.ErrorIs(t, err, ErrNoMatch)
+	assert.Len(t, list, lookupYears)
+
+	_, err = Prev(context.Background(), p, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), tz)
+	assert.ErrorIs(t, err, ErrNoMatch)
+}
+
+func TestPeriod_NoPhase(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	now, _ := time.Parse(SUPPORTEDFORMAT, "20210714T123417Z")
+
+	// The timestamps would start at the requested time itself
+	for _, s := range []string{
+		"PT1H", "P1D", "FREQ=MINUTELY;INTERVAL=7", "FREQ=WEEKLY", "FREQ=DAILY;COUNT=3",
+		`{"union": [{"period": "1d"}, {"period": "PT1H"}]}`,
+		`{"except": [{"period": "1h"}, {"period": "FREQ=DAILY;INTERVAL=2"}]}`,
+		// The multiples that do not tile their unit would be aligned to t1
+		"2d", "5w", "2y", "5mo", "7h", "90m", "2h",
+	} {
+		p, err := ParsePeriod(s)
+		assert.NoError(t, err)
+
+		_, err = Next(context.Background(), p, now, tz)
+		assert.ErrorIs(t, err, ErrNoPhase, s)
+		_, err = Prev(context.Background(), p, now, tz)
+		assert.ErrorIs(t, err, ErrNoPhase, s)
+	}
+
+	// A day in elapsed time drifts from the wall clock at a change
+	p, err := ParsePeriodWithOptions("1d", Options{DST: DST{Stepping: ElapsedStepping}})
+	assert.NoError(t, err)
+	_, err = Next(context.Background(), p, now, tz)
+	assert.ErrorIs(t, err, ErrNoPhase)
+}
+
+func TestPeriod_NextPrevFromAnyTime(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	anchor, _ := ParseAnchor("2021-01-01")
+
+	// The timestamps next to a later time are the ones from an earlier time
+	tests := []struct {
+		period string
+		opts   Options
+	}{
+		{period: "15m"},
+		{period: "1h"},
+		{period: "6h", opts: Options{DST: DST{Stepping: WallStepping}}},
+		{period: "1d"},
+		{period: "1w"},
+		{period: "3mo"},
+		{period: "2q"},
+		{period: "2d", opts: Options{Anchor: anchor}},
+		{period: "7h", opts: Options{Anchor: anchor}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			p, err := ParsePeriodWithOptions(tt.period, tt.opts)
+			assert.NoError(t, err)
+
+			all, err := NextN(context.Background(), p, t1, 12, tz)
+			assert.NoError(t, err)
+			later := all[2].Add(time.Second)
+			list, err := NextN(context.Background(), p, later, 9, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, all[3:], list)
+
+			prev, err := Prev(context.Background(), p, later, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, all[2], prev)
+		})
+	}
+}
+
+func TestPeriod_NextPrev(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	now, _ := time.Parse(SUPPORTEDFORMAT, "20210714T123417Z")
+	anchor, _ := ParseAnchor("20210101T000000Z")
+
+	tests := []struct {
+		period     string
+		anchor     Anchor
+		prev, next string
+	}{
+		{period: "PT1H", anchor: anchor, prev: "20210714T120000Z", next: "20210714T130000Z"},
+		{period: "P1D", anchor: anchor, prev: "20210713T230000Z", next: "20210714T230000Z"},
+		{
+			period: "DTSTART:20210714T000000Z\nRRULE:FREQ=MINUTELY;INTERVAL=7",
+			prev:   "20210714T122900Z", next: "20210714T123600Z",
+		},
+		{period: "FREQ=MINUTELY;INTERVAL=7", anchor: anchor, prev: "20210714T122800Z", next: "20210714T123500Z"},
+		// A rule without a DTSTART whose occurrences do not depend on it
+		{period: "FREQ=HOURLY", prev: "20210714T120000Z", next: "20210714T130000Z"},
+		{period: "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9", prev: "20210714T060000Z", next: "20210719T060000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			p, err := ParsePeriodWithOptions(tt.period, Options{Anchor: tt.anchor})
+			assert.NoError(t, err)
+
+			prev, err := Prev(context.Background(), p, now, tz)
+			assert.NoError(t, err)
+			next, err := Next(context.Background(), p, now, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.prev, prev.UTC().Format(SUPPORTEDFORMAT))
+			assert.Equal(t, tt.next, next.UTC().Format(SUPPORTEDFORMAT))
+
+			// The previous and the next timestamps are next to each other
+			after, err := Next(context.Background(), p, prev, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, next, after)
+			before, err := Prev(context.Background(), p, next, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, prev, before)
+		})
+	}
+}
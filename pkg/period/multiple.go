@@ -0,0 +1,296 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit is the calendar or clock unit that a period steps by.
+type Unit int
+
+// Constants for all supported units
+const (
+	Minute Unit = iota
+	Hour
+	Day
+	Week
+	Month
+	Quarter
+	Year
+)
+
+// unitSuffixes maps the suffix of a period such as 15m or 3mo to its unit.
+var unitSuffixes = map[string]Unit{
+	"m":  Minute,
+	"h":  Hour,
+	"d":  Day,
+	"w":  Week,
+	"mo": Month,
+	"q":  Quarter,
+	"y":  Year,
+}
+
+// Multiple Period, every N units (15m, 6h, 2d, 3mo, ...)
+type MultiplePeriod struct {
+	N    int
+	Unit Unit
+	// WeekStart is the first day of the week for the week unit.
+	WeekStart time.Weekday
+	// At is the invocation point inside every period, the start by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of the periods, which are otherwise aligned to
+	// the unit that contains t1 (see align).
+	Anchor Anchor
+}
+
+func (mp MultiplePeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(mp.Iter(context.Background(), t1, t2, tz))
+}
+
+func (mp MultiplePeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	base, tl, i := mp.schedule(t1, tz)
+
+	// Generate the periodic timestamps. Every period is computed from the
+	// base, so that the calendar units never drift (e.g. 31st + 1 month).
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		start, end := mp.add(base, i*mp.N), mp.add(base, (i+1)*mp.N)
+		i++
+
+		// The next invocation points are at or after the midnight of the next
+		// period, since the time of the day may be before the one of an anchor
+		floor := end.Add(-dstMargin)
+		if mp.Unit >= Day {
+			y, m, d := end.Date()
+			floor = time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Add(-dstMargin)
+		}
+		// Skip the periods without an invocation point
+		c, ok := mp.At.apply(start, end, mp.Unit)
+		if !ok {
+			return nil, floor, true, nil
+		}
+		return tl.instants(c), floor, true, nil
+	})
+}
+
+// schedule returns the wall clock base of the periods generated from t1,
+// their timeline and the index of the first period to generate. The ith
+// period starts at mp.add(base, i*mp.N), and i may be negative.
+func (mp MultiplePeriod) schedule(t1 time.Time, tz *time.Location) (time.Time, timeline, int) {
+	// The periods are computed on the wall clock of the requested timezone
+	// (civil times in UTC), and turned into instants by the DST policy.
+	c1 := toCivil(t1.In(tz))
+	elapsed := mp.DST.elapsed(mp.Unit < Day)
+
+	// Start from the period before the one of t1, since its end may be t1
+	// itself
+	var base time.Time
+	switch {
+	case !mp.Anchor.IsZero():
+		// The periods start at the anchor, whatever t1 is
+		base = mp.Anchor.civil(tz)
+	case elapsed && mp.Unit < Day:
+		// In elapsed time, the minutes and hours are counted from the
+		// local midnight
+		y, m, d := c1.Date()
+		base = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	default:
+		// Start from the beginning of the unit that contains t1, so that
+		// the multiples are aligned, e.g. 15m at :00, :15, ... of the hour
+		// or 3mo at the start of each quarter
+		base = mp.align(c1)
+		return base, mp.DST.timeline(base, tz, elapsed), -1
+	}
+
+	tl := mp.DST.timeline(base, tz, elapsed)
+	if elapsed && mp.Unit < Day {
+		return base, tl, steps(tl.start, t1, mp.step()) - 1
+	}
+	// The whole units from an anchor may be one more than the periods, e.g.
+	// from the 31st to the 1st of the month after the next one
+	return base, tl, floorDiv(mp.units(base, c1), mp.N) - 2
+}
+
+// count returns the number of timestamps in [t1, t2) without generating
+// them. Every period has exactly one timestamp, in order, unless it may lack
+// an invocation point, or the DST policy may skip, repeat or merge wall
+// clocks, in which case it reports false.
+func (mp MultiplePeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	elapsed := mp.DST.elapsed(mp.Unit < Day)
+	if mp.At.Nth != 0 || mp.At.BusinessDay != 0 ||
+		!elapsed && (mp.Unit < Day || mp.DST.Gap == SkipGap || mp.DST.Fold == BothFolds) {
+		return 0, false
+	}
+	if !t1.Before(t2) {
+		return 0, true
+	}
+
+	base, tl, first := mp.schedule(t1, tz)
+
+	// instant returns the timestamp of the ith period
+	instant := func(i int) time.Time {
+		c, _ := mp.At.apply(mp.add(base, i*mp.N), mp.add(base, (i+1)*mp.N), mp.Unit)
+		return tl.instants(c)[0]
+	}
+
+	// index returns the first period whose timestamp is at or after t. It is
+	// estimated from the units since the base, and then corrected by the
+	// few periods that the invocation point and the UTC offset move. It
+	// reports false when they are more, or when ctx is done.
+	index := func(t time.Time) (int, bool) {
+		var i int
+		if elapsed && mp.Unit < Day {
+			i = steps(tl.start, t, mp.step())
+		} else {
+			i = floorDiv(mp.units(base, toCivil(t.In(tz))), mp.N)
+		}
+		if i < first {
+			i = first
+		}
+		for n := 0; i > first && !instant(i-1).Before(t); n++ {
+			if n == maxCorrections || ctx.Err() != nil {
+				return 0, false
+			}
+			i--
+		}
+		for n := 0; instant(i).Before(t); n++ {
+			if n == maxCorrections || ctx.Err() != nil {
+				return 0, false
+			}
+			i++
+		}
+		return i, true
+	}
+
+	i1, ok := index(t1)
+	if !ok {
+		return 0, false
+	}
+	i2, ok := index(t2)
+	return i2 - i1, ok
+}
+
+// step returns the length of the periods of minutes and hours.
+func (mp MultiplePeriod) step() time.Duration {
+	return time.Duration(mp.N) * mp.Unit.duration()
+}
+
+// units returns the number of whole units between the wall clocks from and
+// to.
+func (mp MultiplePeriod) units(from, to time.Time) int {
+	switch mp.Unit {
+	case Minute, Hour:
+		return steps(from, to, mp.Unit.duration())
+	case Day:
+		return daysBetween(from, to)
+	case Week:
+		return daysBetween(from, to) / 7
+	default:
+		return monthsBetween(from, to) / mp.Unit.months()
+	}
+}
+
+func (mp MultiplePeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(mp.Unit, mp.N); err != nil {
+		return nil, err
+	}
+	mp.At = at
+	return mp, nil
+}
+
+func (mp MultiplePeriod) withDST(dst DST) Period {
+	mp.DST = dst
+	return mp
+}
+
+func (mp MultiplePeriod) withAnchor(a Anchor) (Period, error) {
+	mp.Anchor = a
+	return mp, nil
+}
+
+// align returns the first wall clock of the period at or before the wall
+// clock c. Minutes and hours are aligned to the local midnight, months and
+// quarters to the start of the year, and the other units to their own start.
+func (mp MultiplePeriod) align(c time.Time) time.Time {
+	y, m, d := c.Date()
+	switch mp.Unit {
+	case Minute, Hour:
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return midnight.Add(c.Sub(midnight).Truncate(mp.step()))
+	case Day:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	case Week:
+		days := (int(c.Weekday()) - int(mp.WeekStart) + 7) % 7
+		return time.Date(y, m, d-days, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// add adds n units to the wall clock t. The day of the month is clamped to
+// the end of a shorter month, e.g. for an anchor on the 31st.
+func (mp MultiplePeriod) add(t time.Time, n int) time.Time {
+	switch mp.Unit {
+	case Minute:
+		return addSeconds(t, int64(n)*60)
+	case Hour:
+		return addSeconds(t, int64(n)*3600)
+	case Day:
+		return t.AddDate(0, 0, n)
+	case Week:
+		return t.AddDate(0, 0, 7*n)
+	case Month:
+		return addCalendar(t, 0, n, 0)
+	case Quarter:
+		return addCalendar(t, 0, 3*n, 0)
+	default:
+		return addCalendar(t, n, 0, 0)
+	}
+}
+
+// parseMultiple parses a period of the form <N><unit>, e.g. 15m or 3mo.
+func parseMultiple(s string) (Period, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return nil, fmt.Errorf("%q should be of the form <N><unit>", s)
+	}
+
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return nil, fmt.Errorf("%q has an invalid multiple: %v", s, err)
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("%q should have a positive multiple", s)
+	}
+
+	unit, ok := unitSuffixes[s[i:]]
+	if !ok {
+		return nil, fmt.Errorf("%q has an unsupported unit %q "+
+			"(supported units are m, h, d, w, mo, q, y)", s, s[i:])
+	}
+	if n > unit.maxMultiple() {
+		return nil, fmt.Errorf("%q should have a multiple of at most %d", s, unit.maxMultiple())
+	}
+
+	return MultiplePeriod{N: n, Unit: unit, WeekStart: time.Monday}, nil
+}
+
+// maxMultiple returns the largest multiple of the unit whose periods fit in a
+// time.Duration (about 292 years), a month being 31 days at most.
+func (u Unit) maxMultiple() int {
+	length := map[Unit]time.Duration{
+		Minute:  time.Minute,
+		Hour:    time.Hour,
+		Day:     24 * time.Hour,
+		Week:    7 * 24 * time.Hour,
+		Month:   31 * 24 * time.Hour,
+		Quarter: 92 * 24 * time.Hour,
+		Year:    366 * 24 * time.Hour,
+	}[u]
+	return int(time.Duration(math.MaxInt64) / length)
+}
@@ -1,35 +1,60 @@
 package period
 
 import (
+	"context"
 	"time"
 )
 
 // One Day Period
-type OneDayPeriod struct{}
+type OneDayPeriod struct {
+	// At is the invocation point inside every day, the local midnight by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every day, e.g. at 00:15.
+	Anchor Anchor
+}
 
 func (odp OneDayPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
-	var ptlist []string
-
-	// Get the time zone offset of the start time based on the requested timezone.
-	_, offsetSecs := t1.In(tz).Zone()
-
-	// Generate the periodic timestamps for one day,
-	// taking daylight saving time changes into account.
-	for t := t1; t.Before(t2); t = t.Add(24 * time.Hour) {
-		// Get the time zone offset of the current time.
-		_, newOffsetSecs := t.In(tz).Zone()
-		_t := t
-		// Recalculate the offset if different from the start time's offset
-		// Remove the daylight saving time
-		if offsetSecs != newOffsetSecs {
-			_t = _t.Add(time.Duration(offsetSecs-newOffsetSecs) * time.Second)
-		}
-
-		// Round the hour
-		_t = _t.Round(60 * time.Minute)
-		// Append the time to the list
-		ptlist = append(ptlist, _t.Format(SUPPORTEDFORMAT))
+	return odp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (odp OneDayPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return odp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (odp OneDayPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return odp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (odp OneDayPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return odp.multiple().recurrenceRule(tz)
+}
+
+func (odp OneDayPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return odp.multiple().pin(t1, tz)
+}
+
+func (odp OneDayPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Day, 1); err != nil {
+		return nil, err
 	}
+	odp.At = at
+	return odp, nil
+}
+
+func (odp OneDayPeriod) withDST(dst DST) Period {
+	odp.DST = dst
+	return odp
+}
+
+func (odp OneDayPeriod) withAnchor(a Anchor) (Period, error) {
+	odp.Anchor = a
+	return odp, nil
+}
 
-	return ptlist
+// multiple returns the days as a multiple period of one day.
+func (odp OneDayPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Day, At: odp.At.or(StartInvocation), DST: odp.DST,
+		Anchor: odp.Anchor}
 }
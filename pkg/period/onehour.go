@@ -1,31 +1,60 @@
 package period
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // One Hour Period
-type OneHourPeriod struct{}
+type OneHourPeriod struct {
+	// At is the invocation point inside every hour, the start by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every hour, e.g. at 00:15.
+	Anchor Anchor
+}
 
 func (ohp OneHourPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
-	var ptlist []string
-
-	// Get the time zone offset of the start time based on the requested timezone.
-	_, offsetSecs := t1.In(tz).Zone()
-
-	// Round the start time to hour.
-	t1 = t1.Round(60 * time.Minute)
-
-	// Generate the periodic timestamps for one hour
-	for t := t1; t.Before(t2); t = t.Add(time.Hour) {
-		// Get the time zone offset of the current time based on the requested timezone.
-		_, newOffsetSecs := t.In(tz).Zone()
-		_t := t
-		// Recalculate the offset if different from the start time's offset
-		// Remove the daylight saving time
-		if offsetSecs != newOffsetSecs {
-			_t = _t.Add(time.Duration(offsetSecs-newOffsetSecs) * time.Second)
-		}
-
-		ptlist = append(ptlist, _t.Format(SUPPORTEDFORMAT))
+	return ohp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (ohp OneHourPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return ohp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (ohp OneHourPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return ohp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (ohp OneHourPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return ohp.multiple().recurrenceRule(tz)
+}
+
+func (ohp OneHourPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return ohp.multiple().pin(t1, tz)
+}
+
+func (ohp OneHourPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Hour, 1); err != nil {
+		return nil, err
 	}
-	return ptlist
+	ohp.At = at
+	return ohp, nil
+}
+
+func (ohp OneHourPeriod) withDST(dst DST) Period {
+	ohp.DST = dst
+	return ohp
+}
+
+func (ohp OneHourPeriod) withAnchor(a Anchor) (Period, error) {
+	ohp.Anchor = a
+	return ohp, nil
+}
+
+// multiple returns the hours as a multiple period of one hour.
+func (ohp OneHourPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Hour, At: ohp.At.or(StartInvocation), DST: ohp.DST,
+		Anchor: ohp.Anchor}
 }
@@ -1,38 +1,60 @@
 package period
 
 import (
-	"log"
+	"context"
 	"time"
 )
 
 // One Month Period
-type OneMonthPeriod struct{}
+type OneMonthPeriod struct {
+	// At is the invocation point inside every month, the end by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every month, e.g. at 00:15.
+	Anchor Anchor
+}
 
 func (omp OneMonthPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
-	var ptlist []string
-
-	// Get the time zone offset of the start time based on the requested timezone.
-	_, offsetSecs := t1.In(tz).Zone()
-	log.Println("Offset ", time.Duration(offsetSecs)*time.Second)
-
-	// Generate the periodic timestamps for one month
-	for t := t1; t.Before(t2); t = t.AddDate(0, 1, 0) {
-		_t := lastDateOfMonth(t)
-		if _t.After(t2) {
-			break
-		}
-		// Get the time zone offset of the current time based on the requested timezone.
-		_, newOffsetSecs := _t.In(tz).Zone()
-
-		if newOffsetSecs != offsetSecs {
-			// Add offset of start time (removing the daylight saving time)
-			_t = _t.Add(time.Duration(offsetSecs-newOffsetSecs) * time.Second)
-		}
-
-		// Round the hour
-		_t = _t.Round(60 * time.Minute)
-		// Append the time to the list
-		ptlist = append(ptlist, _t.Format(SUPPORTEDFORMAT))
+	return omp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (omp OneMonthPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return omp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (omp OneMonthPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return omp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (omp OneMonthPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return omp.multiple().recurrenceRule(tz)
+}
+
+func (omp OneMonthPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return omp.multiple().pin(t1, tz)
+}
+
+func (omp OneMonthPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Month, 1); err != nil {
+		return nil, err
 	}
-	return ptlist
+	omp.At = at
+	return omp, nil
+}
+
+func (omp OneMonthPeriod) withDST(dst DST) Period {
+	omp.DST = dst
+	return omp
+}
+
+func (omp OneMonthPeriod) withAnchor(a Anchor) (Period, error) {
+	omp.Anchor = a
+	return omp, nil
+}
+
+// multiple returns the months as a multiple period of one month.
+func (omp OneMonthPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Month, At: omp.At.or(EndInvocation), DST: omp.DST,
+		Anchor: omp.Anchor}
 }
@@ -0,0 +1,60 @@
+package period
+
+import (
+	"context"
+	"time"
+)
+
+// One Quarter Period
+type OneQuarterPeriod struct {
+	// At is the invocation point inside every quarter, the end by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every quarter, e.g. at 00:15.
+	Anchor Anchor
+}
+
+func (oqp OneQuarterPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return oqp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (oqp OneQuarterPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return oqp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (oqp OneQuarterPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return oqp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (oqp OneQuarterPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return oqp.multiple().recurrenceRule(tz)
+}
+
+func (oqp OneQuarterPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return oqp.multiple().pin(t1, tz)
+}
+
+func (oqp OneQuarterPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Quarter, 1); err != nil {
+		return nil, err
+	}
+	oqp.At = at
+	return oqp, nil
+}
+
+func (oqp OneQuarterPeriod) withDST(dst DST) Period {
+	oqp.DST = dst
+	return oqp
+}
+
+func (oqp OneQuarterPeriod) withAnchor(a Anchor) (Period, error) {
+	oqp.Anchor = a
+	return oqp, nil
+}
+
+// multiple returns the quarters as a multiple period of one quarter.
+func (oqp OneQuarterPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Quarter, At: oqp.At.or(EndInvocation), DST: oqp.DST,
+		Anchor: oqp.Anchor}
+}
@@ -0,0 +1,63 @@
+package period
+
+import (
+	"context"
+	"time"
+)
+
+// One Week Period
+type OneWeekPeriod struct {
+	// WeekStart is the first day of the week. NewPeriod uses Monday.
+	WeekStart time.Weekday
+	// At is the invocation point inside every week, the end by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every week, e.g. at 00:15.
+	Anchor Anchor
+}
+
+func (owp OneWeekPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return owp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (owp OneWeekPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return owp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (owp OneWeekPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return owp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (owp OneWeekPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return owp.multiple().recurrenceRule(tz)
+}
+
+func (owp OneWeekPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return owp.multiple().pin(t1, tz)
+}
+
+func (owp OneWeekPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Week, 1); err != nil {
+		return nil, err
+	}
+	owp.At = at
+	return owp, nil
+}
+
+func (owp OneWeekPeriod) withDST(dst DST) Period {
+	owp.DST = dst
+	return owp
+}
+
+func (owp OneWeekPeriod) withAnchor(a Anchor) (Period, error) {
+	owp.Anchor = a
+	return owp, nil
+}
+
+// multiple returns the weeks as a multiple period of one week.
+func (owp OneWeekPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Week, WeekStart: owp.WeekStart,
+		At: owp.At.or(EndInvocation), DST: owp.DST,
+		Anchor: owp.Anchor}
+}
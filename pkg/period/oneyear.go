@@ -1,39 +1,60 @@
 package period
 
 import (
+	"context"
 	"time"
 )
 
 // One Year Period
-type OneYearPeriod struct{}
+type OneYearPeriod struct {
+	// At is the invocation point inside every year, the end by default.
+	At Invocation
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the start of every year, e.g. at 00:15.
+	Anchor Anchor
+}
 
 func (oyp OneYearPeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
-	// Get the time zone offset of the start time based on the requested timezone.
-	_, offsetSecs := t1.In(tz).Zone()
-
-	// Adjust t1 at the end of the year
-	t1 = time.Date(t1.Year()+1, 1, 0, t1.Hour(), t1.Minute(), t1.Second(),
-		0, time.UTC)
-
-	// Generate the periodic timestamps for one year
-	var ptlist []string
-	for t := t1; t.Before(t2); t = t.AddDate(1, 0, 0) {
-		_t := lastDateOfMonth(t)
-		if _t.After(t2) {
-			break
-		}
-
-		// Get the time zone offset of the current time based on the requested timezone.
-		_, newOffsetSecs := _t.In(tz).Zone()
-		if newOffsetSecs != offsetSecs {
-			// Add offset of start time (removing the daylight saving time)
-			_t = _t.Add(time.Duration(offsetSecs-newOffsetSecs) * time.Second)
-		}
-
-		// Round the hour
-		_t = _t.Round(60 * time.Minute)
-		// Append the time to the list
-		ptlist = append(ptlist, _t.Format(SUPPORTEDFORMAT))
+	return oyp.multiple().GetMatchingTimestamps(t1, t2, tz)
+}
+
+func (oyp OneYearPeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	return oyp.multiple().Iter(ctx, t1, t2, tz)
+}
+
+func (oyp OneYearPeriod) count(ctx context.Context, t1, t2 time.Time, tz *time.Location) (int, bool) {
+	return oyp.multiple().count(ctx, t1, t2, tz)
+}
+
+func (oyp OneYearPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	return oyp.multiple().recurrenceRule(tz)
+}
+
+func (oyp OneYearPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	return oyp.multiple().pin(t1, tz)
+}
+
+func (oyp OneYearPeriod) withInvocation(at Invocation) (Period, error) {
+	if err := at.validate(Year, 1); err != nil {
+		return nil, err
 	}
-	return ptlist
+	oyp.At = at
+	return oyp, nil
+}
+
+func (oyp OneYearPeriod) withDST(dst DST) Period {
+	oyp.DST = dst
+	return oyp
+}
+
+func (oyp OneYearPeriod) withAnchor(a Anchor) (Period, error) {
+	oyp.Anchor = a
+	return oyp, nil
+}
+
+// multiple returns the years as a multiple period of one year.
+func (oyp OneYearPeriod) multiple() MultiplePeriod {
+	return MultiplePeriod{N: 1, Unit: Year, At: oyp.At.or(EndInvocation), DST: oyp.DST,
+		Anchor: oyp.Anchor}
 }
@@ -1,13 +1,20 @@
 package period
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Constants for all supported periods
 const (
-	ONEHOUR  = "1h"
-	ONEDAY   = "1d"
-	ONEMONTH = "1mo"
-	ONEYEAR  = "1y"
+	ONEHOUR    = "1h"
+	ONEDAY     = "1d"
+	ONEWEEK    = "1w"
+	ONEMONTH   = "1mo"
+	ONEQUARTER = "1q"
+	ONEYEAR    = "1y"
 )
 
 const SUPPORTEDFORMAT = "20060102T150405Z"
@@ -15,27 +22,149 @@ const SUPPORTEDFORMAT = "20060102T150405Z"
 // Period defines the interface of getting the matching timestamps
 // following the rules of the strategy pattern.
 type Period interface {
+	// GetMatchingTimestamps returns the matching timestamps formatted in UTC
+	// and in the SUPPORTEDFORMAT. MatchingTimes returns them as times.
 	GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string
+	// Iter returns an iterator of the matching timestamps in [t1, t2),
+	// which generates them lazily and stops when ctx is done.
+	Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator
+}
+
+// Options tunes how a period is evaluated.
+type Options struct {
+	// At is the invocation point inside the period.
+	At Invocation
+	// Calendar is the name of the holiday calendar, which the service
+	// resolves, and Adjust the business day convention applied with it.
+	Calendar string
+	Adjust   Convention
+	// DST is the daylight saving time policy.
+	DST DST
+	// Anchor fixes the phase of the period, so that its timestamps do not
+	// depend on the requested range.
+	Anchor Anchor
+	// Bounds defines which ends of the requested range match. It applies to
+	// the range, not to the period, so ParsePeriodWithOptions ignores it (see
+	// Bounds.Iter and Bounds.Count).
+	Bounds Bounds
+}
+
+// MatchingTimes returns the matching timestamps of a period in [t1, t2), in
+// the requested timezone. Their UTC time, local time and offset are all at
+// hand, so the callers do not have to parse formatted strings.
+func MatchingTimes(p Period, t1, t2 time.Time, tz *time.Location) []time.Time {
+	list, _ := Collect(p.Iter(context.Background(), t1, t2, tz))
+	return list
+}
+
+// Format formats timestamps in UTC and in the SUPPORTEDFORMAT, which is how
+// GetMatchingTimestamps returns them.
+func Format(list []time.Time) []string {
+	var ptlist []string
+	for _, t := range list {
+		ptlist = append(ptlist, t.UTC().Format(SUPPORTEDFORMAT))
+	}
+	return ptlist
 }
 
-func lastDateOfMonth(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month()+1, 0, t.Hour(), t.Minute(), t.Second(),
-		0, t.Location())
+// invocable is implemented by the periods that support an invocation point.
+type invocable interface {
+	withInvocation(at Invocation) (Period, error)
+}
+
+// dstAware is implemented by the periods that follow a daylight saving
+// time policy.
+type dstAware interface {
+	withDST(dst DST) Period
 }
 
 // NewPeriod returns the behavior of the matching timestamps at the runtime
-// based on the requested period.
+// based on the requested period, which is a registered name (see Register).
+// It returns nil for an unknown name or invalid parameters.
 func NewPeriod(period string) Period {
-	switch period {
-	case ONEHOUR:
-		return OneHourPeriod{}
-	case ONEDAY:
-		return OneDayPeriod{}
-	case ONEMONTH:
-		return OneMonthPeriod{}
-	case ONEYEAR:
-		return OneYearPeriod{}
-	default:
+	p, _, err := lookup(period)
+	if err != nil {
 		return nil
 	}
+	return p
+}
+
+// ParsePeriod returns the period described by s. It accepts the registered
+// periods, such as the period constants (1h, 1d, ...), arbitrary multiples of
+// the form <N><unit> such as 15m, 6h, 2d or 3mo, where the unit is one of m,
+// h, d, w, mo, q or y, ISO 8601 durations such as PT1H, P1M or P1Y6M, cron
+// expressions such as "0 9 * * 1-5" or @daily, RFC 5545 recurrence rules
+// such as FREQ=MONTHLY;BYDAY=-1FR;COUNT=12, and JSON schedule documents that
+// compose them (see Schedule). Supported lists them all.
+func ParsePeriod(s string) (Period, error) {
+	return ParsePeriodWithOptions(s, Options{})
+}
+
+// ParsePeriodWithOptions returns the period described by s, as ParsePeriod
+// does, with the options applied to it.
+func ParsePeriodWithOptions(s string, opts Options) (Period, error) {
+	p, err := parsePeriod(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the invocation point
+	if opts.At.Kind != DefaultInvocation {
+		ip, ok := p.(invocable)
+		if !ok {
+			return nil, fmt.Errorf("%q defines its own invocation point", s)
+		}
+		if p, err = ip.withInvocation(opts.At); err != nil {
+			return nil, fmt.Errorf("%q: %v", s, err)
+		}
+	}
+
+	// Apply the anchor
+	if !opts.Anchor.IsZero() {
+		ap, ok := p.(anchorable)
+		if !ok {
+			return nil, fmt.Errorf("%q does not support an anchor", s)
+		}
+		if p, err = ap.withAnchor(opts.Anchor); err != nil {
+			return nil, fmt.Errorf("%q: %v", s, err)
+		}
+	}
+
+	// Apply the daylight saving time policy
+	if opts.DST != (DST{}) {
+		dp, ok := p.(dstAware)
+		if !ok {
+			return nil, fmt.Errorf("%q does not support a daylight saving time policy", s)
+		}
+		p = dp.withDST(opts.DST)
+	}
+
+	return p, nil
+}
+
+func parsePeriod(s string) (Period, error) {
+	if p, ok, err := lookup(s); ok {
+		return p, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return ParseSchedule([]byte(s))
+	}
+	if strings.Contains(s, "FREQ=") {
+		rp, err := NewRRulePeriod(s)
+		if err != nil {
+			return nil, err
+		}
+		return rp, nil
+	}
+	if strings.HasPrefix(s, "P") {
+		return parseDuration(s)
+	}
+	if strings.HasPrefix(s, "@") || strings.Contains(strings.TrimSpace(s), " ") {
+		cp, err := NewCronPeriod(s)
+		if err != nil {
+			return nil, err
+		}
+		return cp, nil
+	}
+	return parseMultiple(s)
 }
@@ -132,7 +132,967 @@ func TestPeriod_OneYear(t *testing.T) {
 	}
 }
 
+func TestPeriod_OneWeek(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20211010T204603Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211115T123456Z")
+
+	owp := NewPeriod(ONEWEEK)
+	result := owp.GetMatchingTimestamps(t1, t2, tz)
+
+	// The weeks start on Monday, so the period ends on Sunday
+	expected := []string{
+		"20211010T210000Z",
+		"20211017T210000Z",
+		"20211024T210000Z",
+		"20211031T220000Z",
+		"20211107T220000Z",
+		"20211114T220000Z",
+	}
+	if len(result) != len(expected) {
+		t.Errorf("Expected %d timestamps, but got %d", len(expected), len(result))
+		return
+	}
+
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], result[i])
+		}
+	}
+}
+
+func TestPeriod_OneWeekSundayStart(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20211010T204603Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211115T123456Z")
+
+	owp := OneWeekPeriod{WeekStart: time.Sunday}
+	result := owp.GetMatchingTimestamps(t1, t2, tz)
+
+	// The weeks start on Sunday, so the period ends on Saturday
+	expected := []string{
+		"20211016T210000Z",
+		"20211023T210000Z",
+		"20211030T210000Z",
+		"20211106T220000Z",
+		"20211113T220000Z",
+	}
+	if len(result) != len(expected) {
+		t.Errorf("Expected %d timestamps, but got %d", len(expected), len(result))
+		return
+	}
+
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], result[i])
+		}
+	}
+}
+
+func TestPeriod_OneQuarter(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20201130T214603Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211115T123456Z")
+
+	oqp := NewPeriod(ONEQUARTER)
+	result := oqp.GetMatchingTimestamps(t1, t2, tz)
+
+	expected := []string{
+		"20201231T220000Z",
+		"20210331T210000Z",
+		"20210630T210000Z",
+		"20210930T210000Z",
+	}
+	if len(result) != len(expected) {
+		t.Errorf("Expected %d timestamps, but got %d", len(expected), len(result))
+		return
+	}
+
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], result[i])
+		}
+	}
+}
+
+func TestPeriod_Multiple(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name:   "15m",
+			period: "15m",
+			t1:     "20210714T204603Z",
+			t2:     "20210714T220000Z",
+			expected: []string{
+				"20210714T210000Z", "20210714T211500Z", "20210714T213000Z",
+				"20210714T214500Z",
+			},
+		},
+		{
+			name:   "6h",
+			period: "6h",
+			t1:     "20210714T204603Z",
+			t2:     "20210716T000000Z",
+			expected: []string{
+				"20210714T210000Z", "20210715T030000Z", "20210715T090000Z",
+				"20210715T150000Z", "20210715T210000Z",
+			},
+		},
+		{
+			name:   "2d across the DST change",
+			period: "2d",
+			t1:     "20211026T204603Z",
+			t2:     "20211105T000000Z",
+			expected: []string{
+				"20211027T210000Z", "20211029T210000Z", "20211031T220000Z",
+				"20211102T220000Z", "20211104T220000Z",
+			},
+		},
+		{
+			name:   "2w",
+			period: "2w",
+			t1:     "20211010T204603Z",
+			t2:     "20211115T123456Z",
+			expected: []string{
+				"20211017T210000Z", "20211031T220000Z", "20211114T220000Z",
+			},
+		},
+		{
+			name:   "3mo",
+			period: "3mo",
+			t1:     "20210214T214603Z",
+			t2:     "20211115T123456Z",
+			expected: []string{
+				"20210331T210000Z", "20210630T210000Z", "20210930T210000Z",
+			},
+		},
+		{
+			name:   "2q",
+			period: "2q",
+			t1:     "20210214T214603Z",
+			t2:     "20220715T123456Z",
+			expected: []string{
+				"20210630T210000Z", "20211231T220000Z", "20220630T210000Z",
+			},
+		},
+		{
+			name:   "2y",
+			period: "2y",
+			t1:     "20180214T214603Z",
+			t2:     "20231115T123456Z",
+			expected: []string{
+				"20191231T220000Z", "20211231T220000Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			p, err := ParsePeriod(tt.period)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_ParsePeriod(t *testing.T) {
+	p, err := ParsePeriod(ONEMONTH)
+	assert.NoError(t, err)
+	assert.Equal(t, OneMonthPeriod{}, p)
+
+	p, err = ParsePeriod("15m")
+	assert.NoError(t, err)
+	assert.Equal(t, MultiplePeriod{N: 15, Unit: Minute, WeekStart: time.Monday}, p)
+
+	// The largest multiples whose periods fit in a time.Duration
+	for _, s := range []string{"2562047h", "153722867m", "106751d", "9m", "290y"} {
+		_, err := ParsePeriod(s)
+		assert.NoError(t, err, s)
+	}
+
+	for _, s := range []string{"", "m", "0d", "15", "15s", "-2h", "1.5h",
+		"2562048h", "9999999999999h", "106752d", "3444mo", "292y", "99999999999999999999m"} {
+		_, err := ParsePeriod(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPeriod_Duration(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name:   "PT1H across the DST change",
+			period: "PT1H",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T010000Z",
+				"20211031T020000Z",
+			},
+		},
+		{
+			name:   "P1D keeps the local wall clock",
+			period: "P1D",
+			t1:     "20211029T210000Z",
+			t2:     "20211102T000000Z",
+			expected: []string{
+				"20211029T210000Z", "20211030T210000Z", "20211031T220000Z",
+				"20211101T220000Z",
+			},
+		},
+		{
+			name:   "PT24H keeps the elapsed time",
+			period: "PT24H",
+			t1:     "20211029T210000Z",
+			t2:     "20211102T000000Z",
+			expected: []string{
+				"20211029T210000Z", "20211030T210000Z", "20211031T210000Z",
+				"20211101T210000Z",
+			},
+		},
+		{
+			name:   "P1M clamps to the end of the month",
+			period: "P1M",
+			t1:     "20210131T100000Z",
+			t2:     "20210501T000000Z",
+			expected: []string{
+				"20210131T100000Z", "20210228T100000Z", "20210331T090000Z",
+				"20210430T090000Z",
+			},
+		},
+		{
+			name:   "P1Y6M",
+			period: "P1Y6M",
+			t1:     "20200101T120000Z",
+			t2:     "20230101T000000Z",
+			expected: []string{
+				"20200101T120000Z", "20210701T110000Z",
+			},
+		},
+		{
+			name:   "P1W",
+			period: "P1W",
+			t1:     "20211018T060000Z",
+			t2:     "20211108T000000Z",
+			expected: []string{
+				"20211018T060000Z", "20211025T060000Z", "20211101T070000Z",
+			},
+		},
+		{
+			name:   "P1DT12H",
+			period: "P1DT12H",
+			t1:     "20211030T060000Z",
+			t2:     "20211103T000000Z",
+			expected: []string{
+				"20211030T060000Z", "20211031T190000Z", "20211102T070000Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			p, err := ParsePeriod(tt.period)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_ParseDuration(t *testing.T) {
+	p, err := ParsePeriod("P1Y2M3W4DT5H6M7.5S")
+	assert.NoError(t, err)
+	assert.Equal(t, DurationPeriod{
+		Years:  1,
+		Months: 2,
+		Days:   25,
+		Clock:  5*time.Hour + 6*time.Minute + 7500*time.Millisecond,
+	}, p)
+
+	for _, s := range []string{"P", "PT", "P0D", "P1DT", "P1H", "PT1D", "P-1D", "P1M2Y"} {
+		_, err := ParsePeriod(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPeriod_Invocation(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		period   string
+		at       string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name:   "Day 15 of the month",
+			period: ONEMONTH,
+			at:     "day 15 at 09:30",
+			t1:     "20210101T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210115T073000Z", "20210215T073000Z", "20210315T073000Z",
+			},
+		},
+		{
+			name:   "Last day of the month",
+			period: ONEMONTH,
+			at:     "day -1 at 18:00",
+			t1:     "20210101T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210131T160000Z", "20210228T160000Z", "20210331T150000Z",
+			},
+		},
+		{
+			name:   "Day 31 is clamped to the end of the month",
+			period: ONEMONTH,
+			at:     "day 31",
+			t1:     "20210201T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210227T220000Z", "20210330T210000Z",
+			},
+		},
+		{
+			name:   "Start of the month",
+			period: ONEMONTH,
+			at:     "start",
+			t1:     "20210101T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210131T220000Z", "20210228T220000Z", "20210331T210000Z",
+			},
+		},
+		{
+			name:   "Last day of February",
+			period: ONEYEAR,
+			at:     "month 2 day -1",
+			t1:     "20200101T000000Z",
+			t2:     "20220101T000000Z",
+			expected: []string{
+				"20200228T220000Z", "20210227T220000Z",
+			},
+		},
+		{
+			name:   "First day of the last month of the quarter",
+			period: ONEQUARTER,
+			at:     "month -1 day 1 at 12:00",
+			t1:     "20210101T000000Z",
+			t2:     "20220101T000000Z",
+			expected: []string{
+				"20210301T100000Z", "20210601T090000Z", "20210901T090000Z",
+				"20211201T100000Z",
+			},
+		},
+		{
+			name:   "Wednesday of the week",
+			period: ONEWEEK,
+			at:     "day 3 at 12:00",
+			t1:     "20211025T000000Z",
+			t2:     "20211108T000000Z",
+			expected: []string{
+				"20211027T090000Z", "20211103T100000Z",
+			},
+		},
+		{
+			name:   "Time of the day across the DST change",
+			period: ONEDAY,
+			at:     "at 09:30",
+			t1:     "20211030T000000Z",
+			t2:     "20211102T000000Z",
+			expected: []string{
+				"20211030T063000Z", "20211031T073000Z", "20211101T073000Z",
+			},
+		},
+		{
+			name:   "Minute 5 of every hour",
+			period: ONEHOUR,
+			at:     "minute 5 of every hour",
+			t1:     "20210714T204603Z",
+			t2:     "20210714T234603Z",
+			expected: []string{
+				"20210714T210500Z", "20210714T220500Z", "20210714T230500Z",
+			},
+		},
+		{
+			name:   "Offset inside multiple hours",
+			period: "6h",
+			at:     "hour 2 minute 30",
+			t1:     "20210714T000000Z",
+			t2:     "20210715T000000Z",
+			expected: []string{
+				"20210714T053000Z", "20210714T113000Z", "20210714T173000Z",
+				"20210714T233000Z",
+			},
+		},
+		{
+			name:   "2nd Tuesday of the month",
+			period: ONEMONTH,
+			at:     "2nd tuesday at 10:00",
+			t1:     "20210101T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210112T080000Z", "20210209T080000Z", "20210309T080000Z",
+			},
+		},
+		{
+			name:   "Last Friday of the month",
+			period: ONEMONTH,
+			at:     "the last friday of the month",
+			t1:     "20210101T000000Z",
+			t2:     "20210401T000000Z",
+			expected: []string{
+				"20210128T220000Z", "20210225T220000Z", "20210325T220000Z",
+			},
+		},
+		{
+			name:   "5th Friday skips the months without one",
+			period: ONEMONTH,
+			at:     "5th fri",
+			t1:     "20210101T000000Z",
+			t2:     "20210501T000000Z",
+			expected: []string{
+				"20210128T220000Z", "20210429T210000Z",
+			},
+		},
+		{
+			name:   "Last weekday of the month",
+			period: ONEMONTH,
+			at:     "last weekday at 18:00",
+			t1:     "20210101T000000Z",
+			t2:     "20210501T000000Z",
+			expected: []string{
+				"20210129T160000Z", "20210226T160000Z", "20210331T150000Z",
+				"20210430T150000Z",
+			},
+		},
+		{
+			name:   "First business day of the quarter",
+			period: ONEQUARTER,
+			at:     "first business day at 09:00",
+			t1:     "20210101T000000Z",
+			t2:     "20220101T000000Z",
+			expected: []string{
+				"20210101T070000Z", "20210401T060000Z", "20210701T060000Z",
+				"20211001T060000Z",
+			},
+		},
+		{
+			name:   "Last business day of the year",
+			period: ONEYEAR,
+			at:     "last business day",
+			t1:     "20210101T000000Z",
+			t2:     "20230101T000000Z",
+			expected: []string{
+				"20211230T220000Z", "20221229T220000Z",
+			},
+		},
+		{
+			name:   "Last Monday of May",
+			period: ONEYEAR,
+			at:     "month 5 last monday",
+			t1:     "20210101T000000Z",
+			t2:     "20230101T000000Z",
+			expected: []string{
+				"20210530T210000Z", "20220529T210000Z",
+			},
+		},
+		{
+			name:   "Last business day of the week",
+			period: ONEWEEK,
+			at:     "last business day at 17:00",
+			t1:     "20211025T000000Z",
+			t2:     "20211108T000000Z",
+			expected: []string{
+				"20211029T140000Z", "20211105T150000Z",
+			},
+		},
+		{
+			name:   "End of every duration",
+			period: "P1D",
+			at:     "end",
+			t1:     "20211029T210000Z",
+			t2:     "20211102T000000Z",
+			expected: []string{
+				"20211030T210000Z", "20211031T220000Z", "20211101T220000Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			at, err := ParseInvocation(tt.at)
+			assert.NoError(t, err)
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_InvalidInvocation(t *testing.T) {
+	for _, s := range []string{"middle", "day", "day x", "day 0", "at 25:00",
+		"minute -5", "start end", "last", "6th friday", "2nd fortnight"} {
+		_, err := ParseInvocation(s)
+		assert.Error(t, err, s)
+	}
+
+	tests := []struct {
+		period string
+		at     string
+	}{
+		{ONEHOUR, "day 1"},
+		{ONEHOUR, "minute 60"},
+		{ONEDAY, "month 1"},
+		{ONEDAY, "day 2"},
+		{"2d", "day 15"},
+		{"2d", "day -3"},
+		{ONEWEEK, "day 8"},
+		{"2w", "day 15"},
+		{ONEMONTH, "month 2"},
+		{ONEWEEK, "hour 24"},
+		{"0 9 * * *", "start"},
+		{"PT1H", "minute 5"},
+		{ONEWEEK, "2nd tuesday"},
+		{ONEDAY, "first business day"},
+		{ONEMONTH, "day 3 last friday"},
+		{ONEMONTH, "last friday last business day"},
+	}
+	for _, tt := range tests {
+		at, err := ParseInvocation(tt.at)
+		assert.NoError(t, err, tt.at)
+		_, err = ParsePeriodWithOptions(tt.period, Options{At: at})
+		assert.Error(t, err, tt.period+" "+tt.at)
+	}
+}
+
+func TestPeriod_InvocationString(t *testing.T) {
+	tests := map[string]string{
+		"":                                "",
+		"start":                           "start",
+		"END":                             "end",
+		"day 15 at 09:30":                 "day 15 at 09:30",
+		"month -1 day -1 at 18:00":        "month -1 day -1 at 18:00",
+		"minute 5 of every hour":          "at 00:05",
+		"at 00:00":                        "at 00:00",
+		"at 09:30:15":                     "at 09:30:15",
+		"2nd tuesday at 10:00":            "second tuesday at 10:00",
+		"last friday of the month":        "last friday",
+		"last business day":               "last business day",
+		"first weekday":                   "first business day",
+		"day 3 hour 30 minute 5":          "day 3 hour 30 at 00:05",
+		"month 2 day 1 at 12:00":          "month 2 day 1 at 12:00",
+		"day 2 hour 1 minute 90 at 01:00": "day 2 at 03:30",
+	}
+	for s, expected := range tests {
+		at, err := ParseInvocation(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, at.String(), s)
+
+		// The string parses back to the same invocation point
+		again, err := ParseInvocation(at.String())
+		assert.NoError(t, err, s)
+		assert.Equal(t, at, again, s)
+	}
+}
+
 func TestPeriod_UnsupportedPeriod(t *testing.T) {
-	owp := NewPeriod("1w")
-	assert.Nil(t, owp, "Unsupported period")
+	p := NewPeriod("1x")
+	assert.Nil(t, p, "Unsupported period")
+}
+
+// testCalendar has Saturday and Sunday weekends and the holidays it holds.
+type testCalendar map[string]bool
+
+func (c testCalendar) IsBusinessDay(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday &&
+		!c[t.Format("20060102")]
+}
+
+func TestPeriod_Adjusted(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	cal := testCalendar{"20210816": true}
+
+	tests := []struct {
+		name       string
+		period     string
+		at         string
+		calendar   Calendar
+		convention Convention
+		t1, t2     string
+		expected   []string
+	}{
+		{
+			name:       "No adjustment",
+			period:     ONEMONTH,
+			at:         "day 15 at 09:00",
+			calendar:   cal,
+			convention: NoAdjustment,
+			t1:         "20210501T000000Z",
+			t2:         "20210901T000000Z",
+			expected: []string{
+				"20210515T060000Z", "20210615T060000Z", "20210715T060000Z", "20210815T060000Z",
+			},
+		},
+		{
+			name:       "Following",
+			period:     ONEMONTH,
+			at:         "day 15 at 09:00",
+			calendar:   cal,
+			convention: Following,
+			t1:         "20210501T000000Z",
+			t2:         "20210901T000000Z",
+			expected: []string{
+				"20210517T060000Z", "20210615T060000Z", "20210715T060000Z", "20210817T060000Z",
+			},
+		},
+		{
+			name:       "Preceding",
+			period:     ONEMONTH,
+			at:         "day 15 at 09:00",
+			calendar:   cal,
+			convention: Preceding,
+			t1:         "20210501T000000Z",
+			t2:         "20210901T000000Z",
+			expected: []string{
+				"20210514T060000Z", "20210615T060000Z", "20210715T060000Z", "20210813T060000Z",
+			},
+		},
+		{
+			name:       "Skip",
+			period:     ONEMONTH,
+			at:         "day 15 at 09:00",
+			calendar:   cal,
+			convention: Skip,
+			t1:         "20210501T000000Z",
+			t2:         "20210901T000000Z",
+			expected: []string{
+				"20210615T060000Z", "20210715T060000Z",
+			},
+		},
+		{
+			name:       "Modified following stays in the month",
+			period:     ONEMONTH,
+			at:         "day -1 at 09:00",
+			calendar:   cal,
+			convention: ModifiedFollowing,
+			t1:         "20210701T000000Z",
+			t2:         "20211001T000000Z",
+			expected: []string{
+				"20210730T060000Z", "20210831T060000Z", "20210930T060000Z",
+			},
+		},
+		{
+			name:       "Modified preceding stays in the month",
+			period:     ONEMONTH,
+			at:         "day 1 at 09:00",
+			calendar:   cal,
+			convention: ModifiedPreceding,
+			t1:         "20210501T000000Z",
+			t2:         "20210901T000000Z",
+			expected: []string{
+				"20210503T060000Z", "20210601T060000Z", "20210701T060000Z", "20210802T060000Z",
+			},
+		},
+		{
+			name:       "Weekend days merge on Monday",
+			period:     ONEDAY,
+			convention: Following,
+			t1:         "20210701T210000Z",
+			t2:         "20210705T210000Z",
+			expected: []string{
+				"20210701T210000Z", "20210704T210000Z",
+			},
+		},
+		{
+			name:       "Weekend days before the range move into it",
+			period:     ONEDAY,
+			convention: Following,
+			t1:         "20210704T000000Z",
+			t2:         "20210705T210000Z",
+			expected: []string{
+				"20210704T210000Z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+			at, err := ParseInvocation(tt.at)
+			assert.NoError(t, err)
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at})
+			assert.NoError(t, err)
+
+			ap := AdjustedPeriod{Period: p, Calendar: tt.calendar, Convention: tt.convention}
+			assert.Equal(t, tt.expected, ap.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_ParseConvention(t *testing.T) {
+	tests := map[string]Convention{
+		"":                   NoAdjustment,
+		"none":               NoAdjustment,
+		"skip":               Skip,
+		"Following":          Following,
+		"modified-following": ModifiedFollowing,
+		"modified_following": ModifiedFollowing,
+		"preceding":          Preceding,
+		"Modified Preceding": ModifiedPreceding,
+	}
+	for s, expected := range tests {
+		c, err := ParseConvention(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, c, s)
+	}
+
+	_, err := ParseConvention("nearest")
+	assert.Error(t, err)
+
+	for _, c := range []Convention{NoAdjustment, Skip, Following, ModifiedFollowing,
+		Preceding, ModifiedPreceding} {
+		again, err := ParseConvention(c.String())
+		assert.NoError(t, err, c.String())
+		assert.Equal(t, c, again)
+	}
+}
+
+func TestPeriod_DST(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	// The clocks move forward at 20210328T010000Z (03:00 becomes 04:00)
+	// and backward at 20211031T010000Z (04:00 becomes 03:00)
+	tests := []struct {
+		name     string
+		period   string
+		at       string
+		dst      string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name:   "Hours in elapsed time by default",
+			period: ONEHOUR,
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T010000Z", "20211031T020000Z",
+			},
+		},
+		{
+			name:   "Hours on the wall clock fire once in a fold",
+			period: ONEHOUR,
+			dst:    "wall",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T020000Z",
+			},
+		},
+		{
+			name:   "Hours on the wall clock fire twice in a fold",
+			period: ONEHOUR,
+			dst:    "wall,both",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T010000Z", "20211031T020000Z",
+			},
+		},
+		{
+			name:   "Hours on the wall clock merge in a gap",
+			period: ONEHOUR,
+			dst:    "wall",
+			t1:     "20210327T230000Z",
+			t2:     "20210328T030000Z",
+			expected: []string{
+				"20210327T230000Z", "20210328T000000Z", "20210328T010000Z", "20210328T020000Z",
+			},
+		},
+		{
+			name:   "Days in a gap are shifted forward by default",
+			period: ONEDAY,
+			at:     "at 03:30",
+			t1:     "20210326T000000Z",
+			t2:     "20210330T000000Z",
+			expected: []string{
+				"20210326T013000Z", "20210327T013000Z", "20210328T013000Z", "20210329T003000Z",
+			},
+		},
+		{
+			name:   "Days in a gap are skipped",
+			period: ONEDAY,
+			at:     "at 03:30",
+			dst:    "skip",
+			t1:     "20210326T000000Z",
+			t2:     "20210330T000000Z",
+			expected: []string{
+				"20210326T013000Z", "20210327T013000Z", "20210329T003000Z",
+			},
+		},
+		{
+			name:   "Days in a fold fire at the last occurrence",
+			period: ONEDAY,
+			at:     "at 03:30",
+			dst:    "last",
+			t1:     "20211030T000000Z",
+			t2:     "20211102T000000Z",
+			expected: []string{
+				"20211030T003000Z", "20211031T013000Z", "20211101T013000Z",
+			},
+		},
+		{
+			name:   "Days on the wall clock by default",
+			period: ONEDAY,
+			t1:     "20210326T220000Z",
+			t2:     "20210330T000000Z",
+			expected: []string{
+				"20210326T220000Z", "20210327T220000Z", "20210328T210000Z", "20210329T210000Z",
+			},
+		},
+		{
+			name:   "Days in elapsed time",
+			period: ONEDAY,
+			dst:    "elapsed",
+			t1:     "20210326T220000Z",
+			t2:     "20210330T000000Z",
+			expected: []string{
+				"20210326T220000Z", "20210327T220000Z", "20210328T220000Z", "20210329T220000Z",
+			},
+		},
+		{
+			name:     "Months in elapsed time",
+			period:   ONEMONTH,
+			dst:      "elapsed",
+			t1:       "20210301T000000Z",
+			t2:       "20210501T000000Z",
+			expected: []string{"20210331T220000Z", "20210430T220000Z"},
+		},
+		{
+			name:   "ISO 8601 clock part on the wall clock",
+			period: "PT1H",
+			dst:    "wall",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T020000Z",
+			},
+		},
+		{
+			name:   "ISO 8601 calendar part in elapsed time",
+			period: "P1D",
+			dst:    "elapsed",
+			t1:     "20210326T220000Z",
+			t2:     "20210329T000000Z",
+			expected: []string{
+				"20210326T220000Z", "20210327T220000Z", "20210328T220000Z",
+			},
+		},
+		{
+			name:   "Recurrence rule fires twice in a fold",
+			period: "FREQ=HOURLY",
+			dst:    "both",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T010000Z", "20211031T020000Z",
+			},
+		},
+		{
+			name:   "Recurrence rule fires once in a fold by default",
+			period: "FREQ=HOURLY",
+			t1:     "20211030T230000Z",
+			t2:     "20211031T030000Z",
+			expected: []string{
+				"20211030T230000Z", "20211031T000000Z", "20211031T020000Z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+			at, err := ParseInvocation(tt.at)
+			assert.NoError(t, err)
+			dst, err := ParseDST(tt.dst)
+			assert.NoError(t, err)
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at, DST: dst})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_ParseDST(t *testing.T) {
+	dst, err := ParseDST("Wall, skip,both")
+	assert.NoError(t, err)
+	assert.Equal(t, DST{Stepping: WallStepping, Gap: SkipGap, Fold: BothFolds}, dst)
+
+	dst, err = ParseDST("")
+	assert.NoError(t, err)
+	assert.Equal(t, DST{}, dst)
+
+	for _, s := range []string{"later", "wall,", "wall;skip"} {
+		_, err := ParseDST(s)
+		assert.Error(t, err, s)
+	}
+	tests := map[string]string{
+		"":               "shift,first",
+		"wall,skip,both": "wall,skip,both",
+		"last, elapsed":  "elapsed,shift,last",
+		"skip":           "skip,first",
+	}
+	for s, expected := range tests {
+		dst, err := ParseDST(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, dst.String(), s)
+
+		again, err := ParseDST(dst.String())
+		assert.NoError(t, err, s)
+		assert.Equal(t, dst, again, s)
+	}
+}
+
+func TestPeriod_MatchingTimes(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20211029T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211102T000000Z")
+
+	p := NewPeriod(ONEDAY)
+	list := MatchingTimes(p, t1, t2, tz)
+
+	// The local midnights, before and after the DST change
+	offsets := []int{3, 3, 2, 2}
+	if assert.Len(t, list, len(offsets)) {
+		for i, ts := range list {
+			_, offset := ts.Zone()
+			assert.Equal(t, tz, ts.Location())
+			assert.Equal(t, 0, ts.Hour())
+			assert.Equal(t, offsets[i]*60*60, offset)
+		}
+	}
+	assert.Equal(t, p.GetMatchingTimestamps(t1, t2, tz), Format(list))
 }
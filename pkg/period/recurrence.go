@@ -0,0 +1,87 @@
+package period
+
+import (
+	"fmt"
+	"time"
+)
+
+// recurrent is implemented by the periods whose timestamps may follow an
+// RFC 5545 recurrence rule.
+type recurrent interface {
+	recurrenceRule(tz *time.Location) (string, bool)
+}
+
+// RecurrenceRule returns the RFC 5545 recurrence rule that generates the
+// timestamps of p from any of them on the wall clock of tz, such as
+// FREQ=MONTHLY;INTERVAL=3, so that a DTSTART with the TZID of tz at the first
+// timestamp and a COUNT describe them all. It reports false when the
+// timestamps do not map cleanly to a rule, e.g. for the minutes and the hours,
+// which step in elapsed time, or for the days of the month that some months
+// lack.
+func RecurrenceRule(p Period, tz *time.Location) (string, bool) {
+	if r, ok := p.(recurrent); ok {
+		return r.recurrenceRule(tz)
+	}
+	return "", false
+}
+
+// rfcDST reports whether the daylight saving time policy of a period on the
+// wall clock is the one of RFC 5545, which shifts the nonexistent times and
+// keeps the first occurrence of the ambiguous ones.
+func (p DST) rfcDST() bool {
+	return p.Stepping != ElapsedStepping && p.Gap == ShiftGap && p.Fold == FirstFold
+}
+
+func (mp MultiplePeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	if mp.Unit < Day || !mp.DST.rfcDST() {
+		return "", false
+	}
+
+	// A rule repeats the month, the day and the time of the day of DTSTART,
+	// so the days that some months lack are not clean (e.g. the 31st)
+	at := mp.At
+	if at.Kind == OffsetInvocation {
+		if at.Month < 0 || at.Day < 0 || at.Nth != 0 || at.BusinessDay != 0 {
+			return "", false
+		}
+		if mp.Unit >= Month && at.Day > 28 {
+			return "", false
+		}
+	}
+	if mp.Unit >= Month && !mp.Anchor.IsZero() && mp.Anchor.civil(tz).Day() > 28 {
+		return "", false
+	}
+
+	switch mp.Unit {
+	case Day:
+		return recurrence("DAILY", mp.N), true
+	case Week:
+		return recurrence("WEEKLY", mp.N), true
+	case Month:
+		return recurrence("MONTHLY", mp.N), true
+	case Quarter:
+		return recurrence("MONTHLY", 3*mp.N), true
+	default:
+		return recurrence("YEARLY", mp.N), true
+	}
+}
+
+func (dp DurationPeriod) recurrenceRule(tz *time.Location) (string, bool) {
+	// Only the days step on the wall clock without a day of the month
+	if dp.Years != 0 || dp.Months != 0 || dp.Clock != 0 || dp.Days == 0 ||
+		!dp.DST.rfcDST() {
+		return "", false
+	}
+	if dp.Days%7 == 0 {
+		return recurrence("WEEKLY", dp.Days/7), true
+	}
+	return recurrence("DAILY", dp.Days), true
+}
+
+// recurrence returns the rule of the frequency and the interval.
+func recurrence(freq string, interval int) string {
+	if interval == 1 {
+		return "FREQ=" + freq
+	}
+	return fmt.Sprintf("FREQ=%s;INTERVAL=%d", freq, interval)
+}
@@ -0,0 +1,95 @@
+package period
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_RecurrenceRule(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		period   string
+		at       string
+		dst      string
+		anchor   string
+		expected string
+	}{
+		{period: ONEDAY, expected: "FREQ=DAILY"},
+		{period: ONEWEEK, expected: "FREQ=WEEKLY"},
+		{period: ONEMONTH, expected: "FREQ=MONTHLY"},
+		{period: ONEQUARTER, expected: "FREQ=MONTHLY;INTERVAL=3"},
+		{period: ONEYEAR, expected: "FREQ=YEARLY"},
+		{period: "3d", at: "at 09:30", expected: "FREQ=DAILY;INTERVAL=3"},
+		{period: "2w", expected: "FREQ=WEEKLY;INTERVAL=2"},
+		{period: "1mo", at: "day 15 at 18:00", expected: "FREQ=MONTHLY"},
+		{period: "1y", at: "month 2 day 28", expected: "FREQ=YEARLY"},
+		{period: "2mo", anchor: "2021-01-10", expected: "FREQ=MONTHLY;INTERVAL=2"},
+		{period: "P1D", expected: "FREQ=DAILY"},
+		{period: "P2W", expected: "FREQ=WEEKLY;INTERVAL=2"},
+		{period: "1d", dst: "wall", expected: "FREQ=DAILY"},
+
+		// The minutes and hours, the durations with a clock part, and the
+		// policies other than the one of RFC 5545
+		{period: ONEHOUR},
+		{period: "15m"},
+		{period: "PT24H"},
+		{period: "P1M"},
+		{period: "1d", dst: "elapsed"},
+		{period: "1d", at: "at 03:30", dst: "skip"},
+		{period: "1w", at: "first business day"},
+		{period: "1mo", at: "last friday"},
+		{period: "1mo", at: "day -1"},
+		{period: "1mo", at: "day 31"},
+		{period: "1mo", anchor: "2021-01-31"},
+		{period: "0 9 * * *"},
+		{period: "FREQ=DAILY"},
+		{period: `{"union": [{"period": "1d"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s %s %s", tt.period, tt.at, tt.dst, tt.anchor), func(t *testing.T) {
+			at, _ := ParseInvocation(tt.at)
+			dst, _ := ParseDST(tt.dst)
+			anchor, _ := ParseAnchor(tt.anchor)
+			p, err := ParsePeriodWithOptions(tt.period, Options{At: at, DST: dst, Anchor: anchor})
+			assert.NoError(t, err)
+
+			rule, ok := RecurrenceRule(p, tz)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func TestPeriod_RecurrenceRuleMatches(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210101T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20240101T000000Z")
+
+	// The rule from the first timestamp generates them all, across the
+	// daylight saving time changes
+	for _, s := range []string{ONEDAY, ONEWEEK, ONEMONTH, ONEQUARTER, ONEYEAR, "3d", "P10D"} {
+		for _, sat := range []string{"", "at 03:30", "at 04:00"} {
+			t.Run(s+" "+sat, func(t *testing.T) {
+				at, _ := ParseInvocation(sat)
+				p, err := ParsePeriodWithOptions(s, Options{At: at})
+				if err != nil {
+					t.Skip("no invocation point for", s)
+				}
+				rule, ok := RecurrenceRule(p, tz)
+				assert.True(t, ok)
+
+				expected := MatchingTimes(p, t1, t2, tz)
+				first := expected[0].In(tz)
+				rp, err := NewRRulePeriod(fmt.Sprintf("DTSTART;TZID=%s:%s\nRRULE:%s;COUNT=%d",
+					tz, first.Format("20060102T150405"), rule, len(expected)))
+				assert.NoError(t, err)
+
+				assert.Equal(t, Format(expected), rp.GetMatchingTimestamps(t1, t2, tz))
+			})
+		}
+	}
+}
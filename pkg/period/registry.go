@@ -0,0 +1,142 @@
+package period
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory creates the periods of a registered name.
+type Factory struct {
+	// Description describes the period and its parameters.
+	Description string
+	// New returns the period with the given parameters, which follow the
+	// name and a colon (e.g. "15" of "every:15"), and are empty without one.
+	New func(params string) (Period, error)
+}
+
+// Definition is a registered period.
+type Definition struct {
+	Name        string
+	Description string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+func init() {
+	Register(ONEHOUR, Factory{
+		Description: "Every hour, at the start of the hour",
+		New:         static(OneHourPeriod{}),
+	})
+	Register(ONEDAY, Factory{
+		Description: "Every day, at the local midnight",
+		New:         static(OneDayPeriod{}),
+	})
+	Register(ONEWEEK, Factory{
+		Description: "Every week from Monday, at the end of the week",
+		New:         static(OneWeekPeriod{WeekStart: time.Monday}),
+	})
+	Register(ONEMONTH, Factory{
+		Description: "Every month, at the end of the month",
+		New:         static(OneMonthPeriod{}),
+	})
+	Register(ONEQUARTER, Factory{
+		Description: "Every quarter, at the end of the quarter",
+		New:         static(OneQuarterPeriod{}),
+	})
+	Register(ONEYEAR, Factory{
+		Description: "Every year, at the end of the year",
+		New:         static(OneYearPeriod{}),
+	})
+}
+
+// Register makes a period available by name to NewPeriod and ParsePeriod,
+// and so to the service. The period is requested as "name", or as
+// "name:params" with parameters. It panics if the name is empty, contains a
+// colon or is already registered, or if the factory has no New.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("period: invalid name %q", name))
+	}
+	if factory.New == nil {
+		panic("period: Register factory of " + name + " is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("period: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered returns the registered periods, sorted by name.
+func Registered() []Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	defs := make([]Definition, 0, len(registry))
+	for name, f := range registry {
+		defs = append(defs, Definition{Name: name, Description: f.Description})
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// builtins are the forms of the periods that ParsePeriod parses besides the
+// registered names, whose names are their syntax.
+var builtins = []Definition{
+	{Name: "<N><unit>", Description: "Every N units, where the unit is one of m, h, d, w, mo, q " +
+		"or y (e.g. 15m, 6h, 2d or 3mo)"},
+	{Name: "P<duration>", Description: "An ISO 8601 duration (e.g. PT1H, P1D, P1M or P1Y6M), " +
+		"whose calendar part is applied in the timezone and clock part in absolute time"},
+	{Name: "<cron>", Description: "A 5-field or 6-field (with seconds) cron expression " +
+		"(e.g. 0 9 * * 1-5) or a macro (@yearly, @monthly, @weekly, @daily or @hourly)"},
+	{Name: "<rrule>", Description: "An RFC 5545 recurrence rule (e.g. FREQ=MONTHLY;BYDAY=-1FR;COUNT=12), " +
+		"optionally with DTSTART, RRULE, RDATE and EXDATE properties, one per line"},
+	{Name: "<schedule>", Description: "A JSON schedule document, which composes the periods " +
+		"with union, intersect, except, within and outside"},
+}
+
+// Supported returns all the periods that ParsePeriod parses: the registered
+// ones, sorted by name, and then the built-in forms.
+func Supported() []Definition {
+	return append(Registered(), builtins...)
+}
+
+// lookup returns the registered period of s, which is either a name or a
+// name with parameters. It reports false when the name is not registered.
+func lookup(s string) (Period, bool, error) {
+	name, params := s, ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		name, params = s[:i], s[i+1:]
+	}
+
+	registryMu.RLock()
+	f, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	p, err := f.New(params)
+	if err != nil {
+		return nil, true, fmt.Errorf("%q: %v", s, err)
+	}
+	return p, true, nil
+}
+
+// static returns the factory of a period without parameters.
+func static(p Period) func(string) (Period, error) {
+	return func(params string) (Period, error) {
+		if params != "" {
+			return nil, fmt.Errorf("the period has no parameters")
+		}
+		return p, nil
+	}
+}
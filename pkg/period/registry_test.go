@@ -0,0 +1,96 @@
+package period
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Register(t *testing.T) {
+	// A fortnight that starts on the requested weekday, Monday by default
+	Register("fortnight", Factory{
+		Description: "Every two weeks, with an optional week start (e.g. fortnight:0 from Sunday)",
+		New: func(params string) (Period, error) {
+			start := time.Monday
+			if params != "" {
+				wd, err := strconv.Atoi(params)
+				if err != nil || wd < 0 || wd > 6 {
+					return nil, fmt.Errorf("%q is not a weekday from 0 to 6", params)
+				}
+				start = time.Weekday(wd)
+			}
+			return MultiplePeriod{N: 2, Unit: Week, WeekStart: start,
+				At: Invocation{Kind: EndInvocation}}, nil
+		},
+	})
+
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210705T000000Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210720T000000Z")
+
+	p := NewPeriod("fortnight")
+	if assert.NotNil(t, p) {
+		assert.Equal(t, []string{"20210705T000000Z", "20210719T000000Z"},
+			p.GetMatchingTimestamps(t1, t2, time.UTC))
+	}
+
+	p, err := ParsePeriodWithOptions("fortnight:0", Options{DST: DST{Stepping: WallStepping}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20210718T000000Z"}, p.GetMatchingTimestamps(t1, t2, time.UTC))
+
+	_, err = ParsePeriod("fortnight:7")
+	assert.Error(t, err)
+	assert.Nil(t, NewPeriod("fortnight:7"))
+
+	// The constants have no parameters
+	_, err = ParsePeriod("1h:5")
+	assert.Error(t, err)
+
+	var found bool
+	for _, d := range Registered() {
+		found = found || d.Name == "fortnight"
+	}
+	assert.True(t, found, "Expected the registered period to be listed")
+}
+
+func TestPeriod_RegisterPanics(t *testing.T) {
+	noop := func(string) (Period, error) { return OneDayPeriod{}, nil }
+
+	assert.Panics(t, func() { Register(ONEHOUR, Factory{New: noop}) })
+	assert.Panics(t, func() { Register("", Factory{New: noop}) })
+	assert.Panics(t, func() { Register("a:b", Factory{New: noop}) })
+	assert.Panics(t, func() { Register("noop", Factory{}) })
+}
+
+func TestPeriod_Registered(t *testing.T) {
+	defs := Registered()
+
+	var names []string
+	for _, d := range defs {
+		names = append(names, d.Name)
+		assert.NotEmpty(t, d.Description, d.Name)
+	}
+	assert.Subset(t, names, []string{ONEHOUR, ONEDAY, ONEWEEK, ONEMONTH, ONEQUARTER, ONEYEAR})
+	assert.IsNonDecreasing(t, names)
+}
+
+func TestPeriod_Supported(t *testing.T) {
+	defs := Supported()
+	assert.Equal(t, Registered(), defs[:len(defs)-len(builtins)])
+
+	// Every built-in form parses its examples
+	examples := map[string]string{
+		"<N><unit>":   "15m",
+		"P<duration>": "P1Y6M",
+		"<cron>":      "0 9 * * 1-5",
+		"<rrule>":     "FREQ=MONTHLY;BYDAY=-1FR;COUNT=12",
+		"<schedule>":  `{"union":[{"period":"1d"},{"period":"0 12 * * *"}]}`,
+	}
+	for _, d := range defs[len(defs)-len(builtins):] {
+		assert.NotEmpty(t, d.Description, d.Name)
+		_, err := ParsePeriod(examples[d.Name])
+		assert.NoError(t, err, d.Name)
+	}
+}
@@ -0,0 +1,117 @@
+package period
+
+import (
+	"context"
+	"time"
+)
+
+// pinnable is implemented by the periods that can fix the phase that they
+// take from the start of the requested range.
+type pinnable interface {
+	pin(t1 time.Time, tz *time.Location) (Period, bool)
+}
+
+// pin returns a period with the timestamps that p generates in a range from
+// t1, whatever the range, e.g. the multiples aligned to the unit that
+// contains t1 or the ISO 8601 durations from t1 itself, anchored there. It
+// reports false when the phase of p cannot be fixed.
+func pin(p Period, t1 time.Time, tz *time.Location) (Period, bool) {
+	if pp, ok := p.(pinnable); ok {
+		return pp.pin(t1, tz)
+	}
+	return nil, false
+}
+
+// Resume returns an iterator of the timestamps of b.Iter(ctx, p, t1, t2, tz)
+// after the time after, the last one of them that was consumed, e.g. to
+// continue them on another page.
+//
+// The periods that can fix the phase that they take from t1 start right at
+// after, so that a page costs the same wherever it is. The other ones are
+// generated from t1 again, and skip the timestamps up to after.
+func (b Bounds) Resume(ctx context.Context, p Period, t1, t2, after time.Time,
+	tz *time.Location) Iterator {
+	start := t1
+	if after.After(t1) {
+		if pinned, ok := pin(p, t1, tz); ok {
+			p, start = pinned, after
+		}
+	}
+	return &openIterator{Iterator: b.Iter(ctx, p, start, t2, tz), t1: after}
+}
+
+func (mp MultiplePeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	if mp.Anchor.IsZero() {
+		// The periods generated from the base are the same from any other
+		// time after it
+		base, _, _ := mp.schedule(t1, tz)
+		mp.Anchor = Anchor{Time: base, Floating: true}
+	}
+	return mp, true
+}
+
+func (dp DurationPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	if dp.Anchor.IsZero() {
+		dp.Anchor = Anchor{Time: t1}
+	}
+	return dp, true
+}
+
+func (rp RRulePeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	if rp.dtstart.IsZero() && rp.Anchor.IsZero() {
+		// The rule starts at the local midnight of t1
+		y, m, d := t1.In(tz).Date()
+		rp.Anchor = Anchor{Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Floating: true}
+	}
+	return rp, true
+}
+
+func (cp CronPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	// The fire times do not depend on the range
+	return cp, true
+}
+
+func (ap AdjustedPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	// The period is generated from the margin before t1
+	p, ok := pin(ap.Period, t1.Add(-maxAdjustment*24*time.Hour), tz)
+	ap.Period = p
+	return ap, ok
+}
+
+func (up UnionPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	periods, ok := pinAll(up.Periods, t1, tz)
+	return UnionPeriod{Periods: periods}, ok
+}
+
+func (ip IntersectPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	periods, ok := pinAll(ip.Periods, t1, tz)
+	return IntersectPeriod{Periods: periods}, ok
+}
+
+func (ep ExceptPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	periods, ok := pinAll([]Period{ep.Period, ep.Excluded}, t1, tz)
+	if !ok {
+		return nil, false
+	}
+	return ExceptPeriod{Period: periods[0], Excluded: periods[1]}, true
+}
+
+func (wp WindowPeriod) pin(t1 time.Time, tz *time.Location) (Period, bool) {
+	p, ok := pin(wp.Period, t1, tz)
+	wp.Period = p
+	return wp, ok
+}
+
+// pinAll pins all the periods at t1, or reports false when one of them
+// cannot be pinned.
+func pinAll(periods []Period, t1 time.Time, tz *time.Location) ([]Period, bool) {
+	pinned := make([]Period, 0, len(periods))
+	for _, p := range periods {
+		pp, ok := pin(p, t1, tz)
+		if !ok {
+			return nil, false
+		}
+		pinned = append(pinned, pp)
+	}
+	return pinned, true
+}
@@ -0,0 +1,79 @@
+package period
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Resume(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	// t1 is not aligned to any unit, and the range has both changes of the
+	// daylight saving time
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210113T211723Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20211201T000000Z")
+
+	periods := []string{
+		ONEHOUR, ONEDAY, ONEWEEK, ONEMONTH, ONEQUARTER,
+		"15m", "90m", "7h", "3d", "5mo",
+		"PT1H", "PT7H30M", "P1D", "P1M", "P1DT1H",
+		"0 9 * * 1-5", "@hourly",
+		"FREQ=DAILY;INTERVAL=3", "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=5",
+		"DTSTART;TZID=Europe/Athens:20210101T093000\nRRULE:FREQ=WEEKLY;INTERVAL=2",
+		`{"union": [{"period": "3d"}, {"period": "P10D"}]}`,
+		`{"intersect": [{"period": "1d"}, {"period": "7h"}]}`,
+		`{"except": [{"period": "5h"}, {"period": "1d"}]}`,
+		`{"within": {"from": "00:00", "to": "06:00"}, "schedule": {"period": "90m"}}`,
+	}
+	for _, s := range periods {
+		t.Run(s, func(t *testing.T) {
+			p, err := ParsePeriod(s)
+			assert.NoError(t, err)
+			ap := AdjustedPeriod{Period: p, Convention: Following}
+
+			for _, p := range []Period{p, ap} {
+				_, ok := pin(p, t1, tz)
+				assert.True(t, ok, "Expected the period to be pinned")
+				assertResumes(t, p, t1, t2, tz)
+			}
+		})
+	}
+}
+
+func TestPeriod_ResumeUnpinned(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(SUPPORTEDFORMAT, "20210713T211723Z")
+	t2, _ := time.Parse(SUPPORTEDFORMAT, "20210720T000000Z")
+
+	// A period of another package skips the timestamps from t1
+	p := UnionPeriod{Periods: []Period{unpinned{DurationPeriod{Clock: 5 * time.Hour}}}}
+	_, ok := pin(p, t1, tz)
+	assert.False(t, ok)
+	assertResumes(t, p, t1, t2, tz)
+}
+
+// assertResumes asserts that every bounds resume the timestamps of p after
+// any of them.
+func assertResumes(t *testing.T, p Period, t1, t2 time.Time, tz *time.Location) {
+	ctx := context.Background()
+	for _, b := range []Bounds{ClosedOpen, Closed, OpenClosed} {
+		all, err := Collect(b.Iter(ctx, p, t1, t2, tz))
+		assert.NoError(t, err)
+		if len(all) < 2 {
+			t.Fatalf("Expected timestamps, but got %d", len(all))
+		}
+
+		for _, k := range []int{0, 1, len(all) / 3, len(all) / 2, len(all) - 2, len(all) - 1} {
+			got, err := Collect(b.Resume(ctx, p, t1, t2, all[k], tz))
+			assert.NoError(t, err)
+			assert.Equal(t, Format(all[k+1:]), Format(got), "after %s %s", all[k], b)
+		}
+	}
+}
+
+// unpinned hides the phase of a period.
+type unpinned struct {
+	Period
+}
@@ -0,0 +1,649 @@
+package period
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frequency is the FREQ of a recurrence rule.
+type frequency int
+
+// Constants for all supported frequencies
+const (
+	secondly frequency = iota
+	minutely
+	hourly
+	daily
+	weekly
+	monthly
+	yearly
+)
+
+var frequencies = map[string]frequency{
+	"SECONDLY": secondly,
+	"MINUTELY": minutely,
+	"HOURLY":   hourly,
+	"DAILY":    daily,
+	"WEEKLY":   weekly,
+	"MONTHLY":  monthly,
+	"YEARLY":   yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// weekdayNum is a BYDAY value such as MO, 2TU or -1FR.
+type weekdayNum struct {
+	n       int
+	weekday time.Weekday
+}
+
+// RRule Period, described by an RFC 5545 recurrence rule
+// (FREQ=MONTHLY;BYDAY=-1FR;COUNT=12). The rule may be preceded by the
+// DTSTART, RRULE, RDATE and EXDATE properties, one per line.
+//
+// A DTSTART in UTC or with a TZID is expanded in its own location, while a
+// floating DTSTART is expanded in the requested timezone. Without a DTSTART,
+// the rule starts at its anchor, or at the local midnight of t1.
+type RRulePeriod struct {
+	Rule string
+
+	freq       frequency
+	interval   int
+	count      int
+	until      time.Time
+	untilUTC   bool
+	wkst       time.Weekday
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []int
+	bySetPos   []int
+	byHour     []int
+	byMinute   []int
+	bySecond   []int
+
+	dtstart    time.Time
+	dtstartLoc *time.Location
+	rdates     []rdate
+	exdates    []rdate
+
+	// DST is the daylight saving time policy. A rule steps on the wall clock
+	// by default, or in elapsed time from its start.
+	DST DST
+	// Anchor is the start of a rule without a DTSTART, in the requested
+	// timezone.
+	Anchor Anchor
+}
+
+// rdate is a RDATE or EXDATE value. A floating value has no location and
+// is interpreted in the location of the rule.
+type rdate struct {
+	civil time.Time
+	loc   *time.Location
+}
+
+// NewRRulePeriod parses a recurrence rule, optionally preceded by the
+// DTSTART, RRULE, RDATE and EXDATE properties, one per line.
+func NewRRulePeriod(s string) (RRulePeriod, error) {
+	rp := RRulePeriod{Rule: s, interval: 1, wkst: time.Monday}
+
+	rule := ""
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		name, params, value := splitProperty(line)
+		switch name {
+		case "DTSTART":
+			v, err := parseRDate(params, value)
+			if err != nil {
+				return rp, err
+			}
+			rp.dtstart, rp.dtstartLoc = v.civil, v.loc
+		case "RDATE", "EXDATE":
+			for _, x := range strings.Split(value, ",") {
+				v, err := parseRDate(params, x)
+				if err != nil {
+					return rp, err
+				}
+				if name == "RDATE" {
+					rp.rdates = append(rp.rdates, v)
+				} else {
+					rp.exdates = append(rp.exdates, v)
+				}
+			}
+		case "RRULE":
+			rule = value
+		default:
+			// A bare rule without the RRULE property name
+			if name != "" || rule != "" {
+				return rp, fmt.Errorf("%q is not a supported recurrence property", line)
+			}
+			rule = line
+		}
+	}
+	if rule == "" {
+		return rp, fmt.Errorf("%q has no recurrence rule", s)
+	}
+
+	if err := rp.parseRule(rule); err != nil {
+		return rp, err
+	}
+	return rp, nil
+}
+
+// splitProperty splits a content line such as DTSTART;TZID=Europe/Athens:...
+// into its name, parameters and value.
+func splitProperty(line string) (string, map[string]string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", nil, line
+	}
+
+	parts := strings.Split(line[:i], ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = kv[1]
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[i+1:]
+}
+
+// parseRDate parses a DATE or DATE-TIME value. It is in UTC when it ends
+// with Z, in the TZID location when given, and floating otherwise.
+func parseRDate(params map[string]string, value string) (rdate, error) {
+	var v rdate
+	var err error
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		v.civil, err = time.Parse(SUPPORTEDFORMAT, value)
+		v.loc = time.UTC
+	case len(value) == len("20060102"):
+		v.civil, err = time.Parse("20060102", value)
+	default:
+		v.civil, err = time.Parse("20060102T150405", value)
+	}
+	if err != nil {
+		return v, fmt.Errorf("%q is not a valid date or date-time", value)
+	}
+
+	if tzid, ok := params["TZID"]; ok && v.loc == nil {
+		if v.loc, err = time.LoadLocation(tzid); err != nil {
+			return v, fmt.Errorf("%q is not a valid TZID", tzid)
+		}
+	}
+	return v, nil
+}
+
+// parseRule parses the parts of a rule such as FREQ=DAILY;INTERVAL=2.
+func (rp *RRulePeriod) parseRule(rule string) error {
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%q is not a valid rule part", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[key] {
+			return fmt.Errorf("%s is given more than once", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			f, ok := frequencies[value]
+			if !ok {
+				return fmt.Errorf("%q is not a supported FREQ", value)
+			}
+			rp.freq = f
+		case "INTERVAL":
+			rp.interval, err = strconv.Atoi(value)
+			if err == nil && rp.interval <= 0 {
+				err = fmt.Errorf("INTERVAL should be positive")
+			}
+		case "COUNT":
+			rp.count, err = strconv.Atoi(value)
+			if err == nil && rp.count <= 0 {
+				err = fmt.Errorf("COUNT should be positive")
+			}
+		case "UNTIL":
+			var v rdate
+			v, err = parseRDate(nil, value)
+			rp.until, rp.untilUTC = v.civil, v.loc != nil
+		case "WKST":
+			wd, ok := weekdays[value]
+			if !ok {
+				return fmt.Errorf("%q is not a valid WKST", value)
+			}
+			rp.wkst = wd
+		case "BYDAY":
+			rp.byDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rp.byMonthDay, err = parseIntList(key, value, -31, 31)
+		case "BYMONTH":
+			rp.byMonth, err = parseIntList(key, value, 1, 12)
+		case "BYSETPOS":
+			rp.bySetPos, err = parseIntList(key, value, -366, 366)
+		case "BYHOUR":
+			rp.byHour, err = parseIntList(key, value, 0, 23)
+		case "BYMINUTE":
+			rp.byMinute, err = parseIntList(key, value, 0, 59)
+		case "BYSECOND":
+			rp.bySecond, err = parseIntList(key, value, 0, 59)
+		default:
+			return fmt.Errorf("%s is not a supported rule part", key)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+
+	if !seen["FREQ"] {
+		return fmt.Errorf("%q has no FREQ", rule)
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return fmt.Errorf("COUNT and UNTIL should not be given together")
+	}
+	if len(rp.bySetPos) > 0 && len(rp.byDay)+len(rp.byMonthDay)+len(rp.byMonth)+
+		len(rp.byHour)+len(rp.byMinute)+len(rp.bySecond) == 0 {
+		return fmt.Errorf("BYSETPOS should be given with another BYxxx rule part")
+	}
+	for _, wd := range rp.byDay {
+		if wd.n != 0 && rp.freq != monthly && rp.freq != yearly {
+			return fmt.Errorf("BYDAY with a number is only valid for MONTHLY or YEARLY")
+		}
+	}
+	return nil
+}
+
+// parseByDay parses a BYDAY list such as MO,WE or -1FR.
+func parseByDay(value string) ([]weekdayNum, error) {
+	var days []weekdayNum
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("%q is not a valid weekday", v)
+		}
+		wd, ok := weekdays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid weekday", v)
+		}
+		n := 0
+		if num := v[:len(v)-2]; num != "" {
+			var err error
+			n, err = strconv.Atoi(num)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("%q is not a valid weekday", v)
+			}
+		}
+		days = append(days, weekdayNum{n: n, weekday: wd})
+	}
+	return days, nil
+}
+
+// parseIntList parses a comma separated list of integers within [min, max],
+// excluding zero when min is negative.
+func parseIntList(key, value string, min, max int) ([]int, error) {
+	var list []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < min || n > max || (min < 0 && n == 0) {
+			return nil, fmt.Errorf("%q is not a valid %s value", v, key)
+		}
+		list = append(list, n)
+	}
+	sort.Ints(list)
+	return list, nil
+}
+
+func (rp RRulePeriod) GetMatchingTimestamps(t1, t2 time.Time, tz *time.Location) []string {
+	return timestamps(rp.Iter(context.Background(), t1, t2, tz))
+}
+
+// Iter expands the rule on the civil (wall clock) calendar, represented by
+// times in UTC, so that the daylight saving time changes do not affect the
+// calendar arithmetic, and only the occurrences are turned into instants by
+// the DST policy.
+func (rp RRulePeriod) Iter(ctx context.Context, t1, t2 time.Time, tz *time.Location) Iterator {
+	loc := tz
+	if rp.dtstartLoc != nil {
+		loc = rp.dtstartLoc
+	}
+
+	start := rp.dtstart
+	switch {
+	case !start.IsZero():
+	case !rp.Anchor.IsZero():
+		start = rp.Anchor.civil(loc)
+	default:
+		y, m, d := t1.In(loc).Date()
+		start = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	r := rp.withDefaults(start)
+	tl := rp.DST.timeline(start, loc, rp.DST.elapsed(false))
+
+	// Skip the periods before t1, unless they have to be counted
+	first := r.periodStart(start)
+	k := 0
+	if r.count == 0 {
+		k = r.periodsBetween(first, toCivil(t1.In(loc)).Add(-dstMargin))/r.interval - 1
+		if k < 0 {
+			k = 0
+		}
+	}
+
+	// Exclude all the instants of the EXDATEs
+	excluded := make(map[int64]bool)
+	all := DST{Fold: BothFolds}
+	for _, ed := range rp.exdates {
+		for _, t := range all.resolve(ed.civil, ed.location(loc)) {
+			excluded[t.UnixNano()] = true
+		}
+	}
+
+	// The RDATEs come with the first period
+	var instants []time.Time
+	for _, rd := range rp.rdates {
+		instants = append(instants, rp.DST.resolve(rd.civil, rd.location(loc))...)
+	}
+
+	n := 0
+	return newIterator(ctx, t1, t2, tz, func() ([]time.Time, time.Time, bool, error) {
+		p := r.addPeriods(first, k*r.interval)
+		k++
+		floor := r.addPeriods(first, k*r.interval).Add(-dstMargin)
+
+		list := instants
+		instants = nil
+		for _, c := range r.expand(p) {
+			if c.Before(start) {
+				continue
+			}
+			if !r.until.IsZero() &&
+				((r.untilUTC && tl.instant(c).After(r.until)) || (!r.untilUTC && c.After(r.until))) {
+				return exclude(list, excluded), floor, false, nil
+			}
+			n++
+			if r.count > 0 && n > r.count {
+				return exclude(list, excluded), floor, false, nil
+			}
+			list = append(list, tl.instants(c)...)
+		}
+		return exclude(list, excluded), floor, true, nil
+	})
+}
+
+// exclude drops the excluded instants of the list.
+func exclude(list []time.Time, excluded map[int64]bool) []time.Time {
+	kept := list[:0]
+	for _, t := range list {
+		if !excluded[t.UnixNano()] {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+func (rp RRulePeriod) withDST(dst DST) Period {
+	rp.DST = dst
+	return rp
+}
+
+func (rp RRulePeriod) withAnchor(a Anchor) (Period, error) {
+	if !rp.dtstart.IsZero() {
+		return nil, fmt.Errorf("a rule with a DTSTART is already anchored")
+	}
+	rp.Anchor = a
+	return rp, nil
+}
+
+// location returns the location of the value, which is loc for a floating
+// value.
+func (rd rdate) location(loc *time.Location) *time.Location {
+	if rd.loc != nil {
+		return rd.loc
+	}
+	return loc
+}
+
+// withDefaults fills the rule parts that default to the DTSTART.
+func (rp RRulePeriod) withDefaults(start time.Time) RRulePeriod {
+	if len(rp.byDay) == 0 && len(rp.byMonthDay) == 0 {
+		switch rp.freq {
+		case weekly:
+			rp.byDay = []weekdayNum{{weekday: start.Weekday()}}
+		case monthly:
+			rp.byMonthDay = []int{start.Day()}
+		case yearly:
+			if len(rp.byMonth) == 0 {
+				rp.byMonth = []int{int(start.Month())}
+			}
+			rp.byMonthDay = []int{start.Day()}
+		}
+	}
+	if len(rp.byHour) == 0 && rp.freq > hourly {
+		rp.byHour = []int{start.Hour()}
+	}
+	if len(rp.byMinute) == 0 && rp.freq > minutely {
+		rp.byMinute = []int{start.Minute()}
+	}
+	if len(rp.bySecond) == 0 && rp.freq > secondly {
+		rp.bySecond = []int{start.Second()}
+	}
+	return rp
+}
+
+// periodStart returns the start of the FREQ period that contains t.
+func (rp RRulePeriod) periodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch rp.freq {
+	case yearly:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	case monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case weekly:
+		days := (int(t.Weekday()) - int(rp.wkst) + 7) % 7
+		return time.Date(y, m, d-days, 0, 0, 0, 0, time.UTC)
+	case daily:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	case hourly:
+		return t.Truncate(time.Hour)
+	case minutely:
+		return t.Truncate(time.Minute)
+	default:
+		return t.Truncate(time.Second)
+	}
+}
+
+// addPeriods adds n FREQ periods to the period start p.
+func (rp RRulePeriod) addPeriods(p time.Time, n int) time.Time {
+	switch rp.freq {
+	case yearly:
+		return p.AddDate(n, 0, 0)
+	case monthly:
+		return p.AddDate(0, n, 0)
+	case weekly:
+		return p.AddDate(0, 0, 7*n)
+	case daily:
+		return p.AddDate(0, 0, n)
+	case hourly:
+		return addSeconds(p, int64(n)*3600)
+	case minutely:
+		return addSeconds(p, int64(n)*60)
+	default:
+		return addSeconds(p, int64(n))
+	}
+}
+
+// periodsBetween returns the number of whole FREQ periods from p to t. The
+// periods shorter than a month are counted in whole seconds, since a
+// time.Duration saturates after about 292 years.
+func (rp RRulePeriod) periodsBetween(p, t time.Time) int {
+	seconds := t.Unix() - p.Unix()
+	switch rp.freq {
+	case yearly:
+		return t.Year() - p.Year()
+	case monthly:
+		return (t.Year()-p.Year())*12 + int(t.Month()) - int(p.Month())
+	case weekly:
+		return int(seconds / (7 * 86400))
+	case daily:
+		return int(seconds / 86400)
+	case hourly:
+		return int(seconds / 3600)
+	case minutely:
+		return int(seconds / 60)
+	default:
+		return int(seconds)
+	}
+}
+
+// expand returns the sorted occurrences of the FREQ period that starts at p.
+func (rp RRulePeriod) expand(p time.Time) []time.Time {
+	var days []time.Time
+	switch rp.freq {
+	case yearly:
+		for d := p; d.Year() == p.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case monthly:
+		for d := p; d.Month() == p.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case weekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, p.AddDate(0, 0, i))
+		}
+	default:
+		y, m, d := p.Date()
+		days = append(days, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	}
+
+	hours, minutes, seconds := rp.byHour, rp.byMinute, rp.bySecond
+	if rp.freq <= hourly {
+		hours = filterInts([]int{p.Hour()}, rp.byHour)
+	}
+	if rp.freq <= minutely {
+		minutes = filterInts([]int{p.Minute()}, rp.byMinute)
+	}
+	if rp.freq == secondly {
+		seconds = filterInts([]int{p.Second()}, rp.bySecond)
+	}
+
+	var set []time.Time
+	for _, d := range days {
+		if !rp.dayMatches(d) {
+			continue
+		}
+		for _, h := range hours {
+			for _, mi := range minutes {
+				for _, s := range seconds {
+					set = append(set, d.Add(time.Duration(h)*time.Hour+
+						time.Duration(mi)*time.Minute+time.Duration(s)*time.Second))
+				}
+			}
+		}
+	}
+
+	if len(rp.bySetPos) == 0 {
+		return set
+	}
+
+	// Keep only the nth occurrences of the period
+	var selected []time.Time
+	for _, pos := range rp.bySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(set) + pos
+		}
+		if i >= 0 && i < len(set) {
+			selected = append(selected, set[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+// dayMatches reports whether the day d matches the BYMONTH, BYMONTHDAY and
+// BYDAY rule parts.
+func (rp RRulePeriod) dayMatches(d time.Time) bool {
+	if len(rp.byMonth) > 0 && !containsInt(rp.byMonth, int(d.Month())) {
+		return false
+	}
+
+	if len(rp.byMonthDay) > 0 {
+		last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if !containsInt(rp.byMonthDay, d.Day()) &&
+			!containsInt(rp.byMonthDay, d.Day()-last-1) {
+			return false
+		}
+	}
+
+	if len(rp.byDay) > 0 {
+		// The numbered weekdays are relative to the year only for a YEARLY
+		// rule without BYMONTH, and relative to the month otherwise.
+		day, last := d.Day(), time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if rp.freq == yearly && len(rp.byMonth) == 0 {
+			day = d.YearDay()
+			last = time.Date(d.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		}
+
+		matches := false
+		for _, wd := range rp.byDay {
+			if wd.weekday != d.Weekday() {
+				continue
+			}
+			if wd.n == 0 || wd.n == (day-1)/7+1 || wd.n == -((last-day)/7+1) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterInts returns the values that are in the filter, or all the values
+// for an empty filter.
+func filterInts(values, filter []int) []int {
+	if len(filter) == 0 {
+		return values
+	}
+	var list []int
+	for _, v := range values {
+		if containsInt(filter, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// toCivil returns the wall clock of t as a time in UTC.
+func toCivil(t time.Time) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+	return time.Date(y, m, d, hh, mm, ss, t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,190 @@
+package period
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_RRule(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+
+	tests := []struct {
+		name     string
+		rule     string
+		t1, t2   string
+		expected []string
+	}{
+		{
+			name: "Last Friday of the month",
+			rule: "DTSTART:20210101T170000\nRRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=12",
+			t1:   "20210101T000000Z",
+			t2:   "20230101T000000Z",
+			expected: []string{
+				"20210129T150000Z", "20210226T150000Z", "20210326T150000Z",
+				"20210430T140000Z", "20210528T140000Z", "20210625T140000Z",
+				"20210730T140000Z", "20210827T140000Z", "20210924T140000Z",
+				"20211029T140000Z", "20211126T150000Z", "20211231T150000Z",
+			},
+		},
+		{
+			name: "DTSTART more than 292 years before t1",
+			rule: "DTSTART:17000101T000000Z\nRRULE:FREQ=SECONDLY;INTERVAL=3600",
+			t1:   "20260101T000000Z",
+			t2:   "20260101T050000Z",
+			expected: []string{
+				"20260101T000000Z", "20260101T010000Z", "20260101T020000Z",
+				"20260101T030000Z", "20260101T040000Z",
+			},
+		},
+		{
+			name: "Daily with a DTSTART more than 292 years before t1",
+			rule: "DTSTART:17000101T120000Z\nRRULE:FREQ=DAILY;INTERVAL=5",
+			t1:   "20260101T000000Z",
+			t2:   "20260110T000000Z",
+			expected: []string{
+				"20260102T120000Z", "20260107T120000Z",
+			},
+		},
+		{
+			name: "Without DTSTART",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9",
+			t1:   "20211025T000000Z",
+			t2:   "20211104T000000Z",
+			expected: []string{
+				"20211025T060000Z", "20211027T060000Z", "20211101T070000Z",
+				"20211103T070000Z",
+			},
+		},
+		{
+			name: "Last weekday of the month",
+			rule: "DTSTART:20210101T000000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;BYHOUR=18",
+			t1:   "20210101T000000Z",
+			t2:   "20210501T000000Z",
+			expected: []string{
+				"20210129T160000Z", "20210226T160000Z", "20210331T150000Z",
+				"20210430T150000Z",
+			},
+		},
+		{
+			name: "Last day of January and July in UTC",
+			rule: "DTSTART:20200101T120000Z\nRRULE:FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=-1",
+			t1:   "20200101T000000Z",
+			t2:   "20210801T000000Z",
+			expected: []string{
+				"20200131T120000Z", "20200731T120000Z", "20210131T120000Z",
+				"20210731T120000Z",
+			},
+		},
+		{
+			name: "Every other day in the TZID until a date",
+			rule: "DTSTART;TZID=America/New_York:20211105T090000\n" +
+				"RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20211112T000000Z",
+			t1: "20211101T000000Z",
+			t2: "20211201T000000Z",
+			expected: []string{
+				"20211105T130000Z", "20211107T140000Z", "20211109T140000Z",
+				"20211111T140000Z",
+			},
+		},
+		{
+			name: "EXDATE and RDATE",
+			rule: "DTSTART:20211101T100000\nRRULE:FREQ=DAILY;COUNT=5\n" +
+				"EXDATE:20211103T100000\nRDATE:20211110T100000,20211102T100000",
+			t1: "20211101T000000Z",
+			t2: "20211201T000000Z",
+			expected: []string{
+				"20211101T080000Z", "20211102T080000Z", "20211104T080000Z",
+				"20211105T080000Z", "20211110T080000Z",
+			},
+		},
+		{
+			name: "Long after DTSTART",
+			rule: "DTSTART:20210101T000000Z\nRRULE:FREQ=HOURLY;INTERVAL=5",
+			t1:   "20210301T010000Z",
+			t2:   "20210301T120000Z",
+			expected: []string{
+				"20210301T040000Z", "20210301T090000Z",
+			},
+		},
+		{
+			name: "The 31st skips the shorter months",
+			rule: "DTSTART:20210101T000000Z\nRRULE:FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			t1:   "20210101T000000Z",
+			t2:   "20220101T000000Z",
+			expected: []string{
+				"20210131T000000Z", "20210331T000000Z", "20210531T000000Z",
+			},
+		},
+		{
+			name: "20th Monday of the year",
+			rule: "DTSTART:20210101T000000Z\nRRULE:FREQ=YEARLY;BYDAY=20MO",
+			t1:   "20210101T000000Z",
+			t2:   "20230101T000000Z",
+			expected: []string{
+				"20210517T000000Z", "20220516T000000Z",
+			},
+		},
+		{
+			name: "Friday the 13th",
+			rule: "DTSTART:20210101T000000Z\nRRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			t1:   "20210101T000000Z",
+			t2:   "20220601T000000Z",
+			expected: []string{
+				"20210813T000000Z", "20220513T000000Z",
+			},
+		},
+		{
+			name: "WKST=MO",
+			rule: "DTSTART:19970805T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+			t1:   "19970101T000000Z",
+			t2:   "19980101T000000Z",
+			expected: []string{
+				"19970805T090000Z", "19970810T090000Z", "19970819T090000Z",
+				"19970824T090000Z",
+			},
+		},
+		{
+			name: "WKST=SU",
+			rule: "DTSTART:19970805T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			t1:   "19970101T000000Z",
+			t2:   "19980101T000000Z",
+			expected: []string{
+				"19970805T090000Z", "19970817T090000Z", "19970819T090000Z",
+				"19970831T090000Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t1, _ := time.Parse(SUPPORTEDFORMAT, tt.t1)
+			t2, _ := time.Parse(SUPPORTEDFORMAT, tt.t2)
+
+			p, err := ParsePeriod(tt.rule)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.GetMatchingTimestamps(t1, t2, tz))
+		})
+	}
+}
+
+func TestPeriod_InvalidRRule(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=SOMETIMES",
+		"FREQ=DAILY;COUNT=2;UNTIL=20210101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYWEEKNO=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYMONTHDAY=0",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"DTSTART:2021\nRRULE:FREQ=DAILY",
+		"DTSTART;TZID=Nowhere/City:20210101T000000\nRRULE:FREQ=DAILY",
+		"DTSTART:20210101T000000\nSUMMARY:FREQ=DAILY",
+	} {
+		_, err := ParsePeriod(rule)
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,211 @@
+package period
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxScheduleDepth bounds the nesting of a schedule document.
+const maxScheduleDepth = 32
+
+// Schedule is a JSON document that composes periods. Every node is either a
+// period, with its optional invocation point, daylight saving time policy and
+// anchor, or a combination of other nodes:
+//
+//	{"period": "90m", "at": "end", "dst": "wall", "anchor": "2020-01-01T00:15"}
+//	{"union": [<node>, ...]}
+//	{"intersect": [<node>, ...]}
+//	{"except": [<node>, <excluded node>, ...]}
+//	{"within": <window>, "schedule": <node>}
+//	{"outside": <window>, "schedule": <node>}
+//
+// where a window looks like
+//
+//	{"weekdays": ["sat", "sun"], "from": "00:00", "to": "06:00",
+//	 "start": "2021-01-01", "end": "2021-12-31"}
+//
+// and all of its fields are optional. For example, every hour except from
+// 00:00 to 06:00 on weekends is
+//
+//	{"outside": {"weekdays": ["sat", "sun"], "from": "00:00", "to": "06:00"},
+//	 "schedule": {"period": "1h"}}
+type Schedule struct {
+	Period    string      `json:"period,omitempty"`
+	At        string      `json:"at,omitempty"`
+	DST       string      `json:"dst,omitempty"`
+	Anchor    string      `json:"anchor,omitempty"`
+	Union     []Schedule  `json:"union,omitempty"`
+	Intersect []Schedule  `json:"intersect,omitempty"`
+	Except    []Schedule  `json:"except,omitempty"`
+	Within    *WindowSpec `json:"within,omitempty"`
+	Outside   *WindowSpec `json:"outside,omitempty"`
+	Schedule  *Schedule   `json:"schedule,omitempty"`
+}
+
+// WindowSpec is the JSON form of a Window.
+type WindowSpec struct {
+	Weekdays []string `json:"weekdays,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Start    string   `json:"start,omitempty"`
+	End      string   `json:"end,omitempty"`
+}
+
+// ParseSchedule parses a JSON schedule document into its period.
+func ParseSchedule(data []byte) (Period, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var s Schedule
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
+	}
+	return s.period(0)
+}
+
+// period returns the period of a node at the given depth.
+func (s Schedule) period(depth int) (Period, error) {
+	if depth > maxScheduleDepth {
+		return nil, fmt.Errorf("a schedule should be nested at most %d times", maxScheduleDepth)
+	}
+
+	var kinds []string
+	for kind, set := range map[string]bool{
+		"period":    s.Period != "",
+		"union":     s.Union != nil,
+		"intersect": s.Intersect != nil,
+		"except":    s.Except != nil,
+		"within":    s.Within != nil,
+		"outside":   s.Outside != nil,
+	} {
+		if set {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("a schedule should have exactly one of period, union, " +
+			"intersect, except, within or outside")
+	}
+	if s.Period == "" && (s.At != "" || s.DST != "" || s.Anchor != "") {
+		return nil, fmt.Errorf("at, dst and anchor are only valid with a period")
+	}
+	if (s.Within == nil && s.Outside == nil) != (s.Schedule == nil) {
+		return nil, fmt.Errorf("a schedule is only valid, and required, with within or outside")
+	}
+
+	switch kinds[0] {
+	case "period":
+		return s.leaf()
+	case "union":
+		periods, err := schedules(s.Union, depth)
+		if err != nil {
+			return nil, err
+		}
+		return UnionPeriod{Periods: periods}, nil
+	case "intersect":
+		periods, err := schedules(s.Intersect, depth)
+		if err != nil {
+			return nil, err
+		}
+		return IntersectPeriod{Periods: periods}, nil
+	case "except":
+		if len(s.Except) < 2 {
+			return nil, fmt.Errorf("except should have a schedule and at least one excluded")
+		}
+		periods, err := schedules(s.Except, depth)
+		if err != nil {
+			return nil, err
+		}
+		ep := ExceptPeriod{Period: periods[0], Excluded: periods[1]}
+		if len(periods) > 2 {
+			ep.Excluded = UnionPeriod{Periods: periods[1:]}
+		}
+		return ep, nil
+	default:
+		spec, outside := s.Within, false
+		if spec == nil {
+			spec, outside = s.Outside, true
+		}
+		w, err := spec.window()
+		if err != nil {
+			return nil, err
+		}
+		p, err := s.Schedule.period(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return WindowPeriod{Period: p, Window: w, Outside: outside}, nil
+	}
+}
+
+// leaf returns the period of a node with a period.
+func (s Schedule) leaf() (Period, error) {
+	at, err := ParseInvocation(s.At)
+	if err != nil {
+		return nil, fmt.Errorf("%q has an invalid invocation point: %v", s.Period, err)
+	}
+	dst, err := ParseDST(s.DST)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := ParseAnchor(s.Anchor)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePeriodWithOptions(s.Period, Options{At: at, DST: dst, Anchor: anchor})
+}
+
+// schedules returns the periods of the nodes at the given depth.
+func schedules(nodes []Schedule, depth int) ([]Period, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("a combination should have at least one schedule")
+	}
+	periods := make([]Period, 0, len(nodes))
+	for _, n := range nodes {
+		p, err := n.period(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, nil
+}
+
+// window returns the window of its JSON form.
+func (spec WindowSpec) window() (Window, error) {
+	var w Window
+	for _, name := range spec.Weekdays {
+		wd, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return w, fmt.Errorf("%q is not a weekday", name)
+		}
+		w.Weekdays = append(w.Weekdays, wd)
+	}
+
+	var err error
+	if spec.From != "" {
+		if w.From, err = parseClock(spec.From); err != nil {
+			return w, err
+		}
+	}
+	if spec.To != "" {
+		if w.To, err = parseClock(spec.To); err != nil {
+			return w, err
+		}
+	}
+
+	if spec.Start != "" {
+		if w.Start, err = time.Parse("2006-01-02", spec.Start); err != nil {
+			return w, fmt.Errorf("%q is not a valid date (YYYY-MM-DD)", spec.Start)
+		}
+	}
+	if spec.End != "" {
+		if w.End, err = time.Parse("2006-01-02", spec.End); err != nil {
+			return w, fmt.Errorf("%q is not a valid date (YYYY-MM-DD)", spec.End)
+		}
+	}
+	return w, nil
+}
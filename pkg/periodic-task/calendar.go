@@ -0,0 +1,256 @@
+package periodictask
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"periodic-task/pkg/period"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// mediaCalendar is the media type of an RFC 5545 iCalendar export
+const mediaCalendar = "text/calendar"
+
+// The layouts of an RFC 5545 DATE-TIME, on the wall clock of a TZID or in UTC
+const (
+	icsLocal = "20060102T150405"
+	icsUTC   = "20060102T150405Z"
+)
+
+// maxLine is the maximum length of an RFC 5545 content line in octets
+const maxLine = 75
+
+// icsText escapes a TEXT value of RFC 5545
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+// writeCalendar streams the matching timestamps as an RFC 5545 VCALENDAR
+// with the VTIMEZONE of the requested timezone. When the period maps cleanly
+// to a recurrence rule, it is a single VEVENT with the rule and the number
+// of timestamps, and otherwise a VEVENT per timestamp.
+func (h *PeriodHandler) writeCalendar(w http.ResponseWriter, r *http.Request, q query,
+	t1, t2 time.Time) {
+	rule, ok, err := h.S.GetPTRule(r.Context(), q.period, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	it, err := h.S.IterPTList(r.Context(), q.period, t1, t2, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	// The first two timestamps tell whether the rule generates them from
+	// the first one. An error before them is still reported as such.
+	var head []time.Time
+	more := it.Next()
+	for ; more && len(head) < 2; more = it.Next() {
+		head = append(head, it.Time())
+	}
+	if err := it.Err(); err != nil {
+		h.iterationError(w, err)
+		return
+	}
+
+	// The changes of the zone are found before the response is written, since
+	// the context of the request may stop their search over a long range
+	start := t1.Truncate(time.Second)
+	changes, err := transitions(r.Context(), q.tz, start, t2)
+	if err != nil {
+		h.iterationError(w, err)
+		return
+	}
+
+	n := 0
+	if ok && len(head) == 2 && recurs(head[0], head[1], q.tz) {
+		if n, err = h.S.GetPTCount(r.Context(), q.period, t1, t2, q.tz, q.opts); err != nil {
+			h.iterationError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType(mediaCalendar))
+	cal := &calendar{w: w, period: q.period, tz: q.tz, stamp: time.Now().UTC()}
+	cal.begin(start, changes)
+	if n > 0 {
+		cal.event(head[0], rule+";COUNT="+strconv.Itoa(n))
+	} else {
+		for _, t := range head {
+			cal.event(t, "")
+		}
+		for ; more && cal.err == nil; more = it.Next() {
+			cal.event(it.Time(), "")
+		}
+		if err := it.Err(); err != nil {
+			h.L.Error("the calendar is cut short: ", err)
+			return
+		}
+	}
+	cal.end()
+
+	if cal.err != nil {
+		h.L.Error(cal.err.Error())
+	}
+}
+
+// recurs reports whether a rule from the wall clock of the first timestamp
+// generates the next one: the first timestamp is neither shifted forward
+// from a daylight saving time gap, nor the second occurrence of an ambiguous
+// time, nor a fraction of a second that a DATE-TIME cannot hold.
+func recurs(first, next time.Time, tz *time.Location) bool {
+	a, b := first.In(tz), next.In(tz)
+	return a.Nanosecond() == 0 && period.Locate(a, tz).Equal(a) &&
+		a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// calendar writes the content lines of a VCALENDAR, and keeps the first
+// error.
+type calendar struct {
+	w      io.Writer
+	period string
+	tz     *time.Location
+	stamp  time.Time
+	err    error
+}
+
+// begin starts the VCALENDAR, with the VTIMEZONE of the range from start,
+// whose zone changes at the given instants.
+func (c *calendar) begin(start time.Time, changes []time.Time) {
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//periodic-task//periodic-task//EN")
+	c.line("CALSCALE", "GREGORIAN")
+
+	c.line("BEGIN", "VTIMEZONE")
+	c.line("TZID", c.tz.String())
+	// The observance at the start, and the ones of every change after it
+	c.observance(start, start)
+	for _, t := range changes {
+		c.observance(t.Add(-time.Second), t)
+	}
+	c.line("END", "VTIMEZONE")
+}
+
+// observance writes the observance that starts at t, changing the zone of
+// before.
+func (c *calendar) observance(before, t time.Time) {
+	kind := "STANDARD"
+	if t.In(c.tz).IsDST() {
+		kind = "DAYLIGHT"
+	}
+	_, from := before.In(c.tz).Zone()
+	name, to := t.In(c.tz).Zone()
+
+	c.line("BEGIN", kind)
+	// The start is on the wall clock before the change
+	c.line("DTSTART", t.In(time.FixedZone("", from)).Format(icsLocal))
+	c.line("TZOFFSETFROM", utcOffset(from))
+	c.line("TZOFFSETTO", utcOffset(to))
+	c.line("TZNAME", icsText.Replace(name))
+	c.line("END", kind)
+}
+
+// event writes the VEVENT of the timestamp t, with an optional rule.
+func (c *calendar) event(t time.Time, rule string) {
+	h := fnv.New32a()
+	_, _ = io.WriteString(h, c.tz.String()+" "+c.period)
+
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", fmt.Sprintf("%s-%08x@periodic-task", t.UTC().Format(icsUTC), h.Sum32()))
+	c.line("DTSTAMP", c.stamp.Format(icsUTC))
+	// The wall clock of an ambiguous time is the first occurrence, so the
+	// second one is in UTC
+	if local := t.In(c.tz); period.Locate(local, c.tz).Equal(local) {
+		c.line("DTSTART;TZID="+c.tz.String(), local.Format(icsLocal))
+	} else {
+		c.line("DTSTART", t.UTC().Format(icsUTC))
+	}
+	if rule != "" {
+		c.line("RRULE", rule)
+	}
+	c.line("SUMMARY", icsText.Replace(c.period))
+	c.line("END", "VEVENT")
+}
+
+// end ends the VCALENDAR.
+func (c *calendar) end() {
+	c.line("END", "VCALENDAR")
+}
+
+// line writes a content line, folded into lines of at most 75 octets.
+func (c *calendar) line(name, value string) {
+	if c.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	s, limit := name+":"+value, maxLine
+	for len(s) > limit {
+		// Fold between the characters, and the next line starts with a space
+		i := limit
+		for !utf8.RuneStart(s[i]) {
+			i--
+		}
+		b.WriteString(s[:i])
+		b.WriteString("\r\n ")
+		s, limit = s[i:], maxLine-1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	_, c.err = io.WriteString(c.w, b.String())
+}
+
+// transitions returns the instants in (t1, t2] where the offset or the name
+// of the zone of tz changes, for a t1 at a whole second. It stops with the
+// error of ctx.
+func transitions(ctx context.Context, tz *time.Location, t1, t2 time.Time) ([]time.Time, error) {
+	var list []time.Time
+	name, offset := t1.In(tz).Zone()
+	for t := t1; t.Before(t2); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next := t.Add(24 * time.Hour)
+		if next.After(t2) {
+			next = t2
+		}
+		if n, o := next.In(tz).Zone(); n == name && o == offset {
+			t = next
+			continue
+		}
+
+		// The change is in (t, next], at a whole second
+		lo, hi := t.Unix(), next.Unix()
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			if n, o := time.Unix(mid, 0).In(tz).Zone(); n == name && o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		t = time.Unix(hi, 0)
+		list = append(list, t)
+		name, offset = t.In(tz).Zone()
+	}
+	return list, nil
+}
+
+// utcOffset formats an offset in seconds as an RFC 5545 UTC-OFFSET.
+func utcOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf("%02d", offset%60)
+	}
+	return s
+}
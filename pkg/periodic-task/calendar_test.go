@@ -0,0 +1,152 @@
+package periodictask
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestPeriodHandler_Calendar(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ph := &PeriodHandler{
+		S: NewService(logger.Sugar(), nil, Limits{}),
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("RecurrenceRule", func(t *testing.T) {
+		// Every day at 09:00 local, across the change to summer time
+		rr := get("/ptlist?period=1d&at=at+09:00&tz=Europe/Athens"+
+			"&t1=20210327T000000Z&t2=20210330T000000Z", "text/calendar")
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "text/calendar; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+		body := rr.Body.String()
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT\r\n"))
+		assert.Contains(t, body, "DTSTART;TZID=Europe/Athens:20210327T090000\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=DAILY;COUNT=3\r\n")
+		assert.Contains(t, body, "SUMMARY:1d\r\n")
+
+		// The observances at t1 and after the change
+		assert.Contains(t, body, "BEGIN:VTIMEZONE\r\nTZID:Europe/Athens\r\n")
+		assert.Contains(t, body, "BEGIN:DAYLIGHT\r\nDTSTART:20210328T030000\r\n"+
+			"TZOFFSETFROM:+0200\r\nTZOFFSETTO:+0300\r\nTZNAME:EEST\r\nEND:DAYLIGHT\r\n")
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	})
+
+	t.Run("EventPerTimestamp", func(t *testing.T) {
+		rr := get("/ptlist?period=1h&tz=Europe/Athens&format=ics"+
+			"&t1=20210729T000000Z&t2=20210729T030000Z", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		body := rr.Body.String()
+		assert.Equal(t, 3, strings.Count(body, "BEGIN:VEVENT\r\n"))
+		assert.NotContains(t, body, "RRULE")
+		for _, ts := range []string{"20210729T030000", "20210729T040000", "20210729T050000"} {
+			assert.Contains(t, body, "DTSTART;TZID=Europe/Athens:"+ts+"\r\n")
+		}
+		assert.Contains(t, body, "BEGIN:DAYLIGHT\r\nDTSTART:20210729T030000\r\n")
+	})
+
+	t.Run("AmbiguousTimes", func(t *testing.T) {
+		// 03:00 local occurs twice, and the second one is in UTC
+		rr := get("/ptlist?period=1h&tz=Europe/Athens&format=ics"+
+			"&t1=20211031T000000Z&t2=20211031T020000Z", "")
+
+		body := rr.Body.String()
+		assert.Contains(t, body, "DTSTART;TZID=Europe/Athens:20211031T030000\r\n")
+		assert.Contains(t, body, "DTSTART:20211031T010000Z\r\n")
+	})
+
+	t.Run("ShiftedStart", func(t *testing.T) {
+		// 03:30 local does not exist on the first day, so the rule from it
+		// would not hold
+		rr := get("/ptlist?period=1d&at=at+03:30&tz=Europe/Athens&format=ics"+
+			"&t1=20210327T220000Z&t2=20210330T000000Z", "")
+
+		body := rr.Body.String()
+		assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT\r\n"))
+		assert.NotContains(t, body, "RRULE")
+	})
+
+	t.Run("NoTimestamps", func(t *testing.T) {
+		rr := get("/ptlist?period=1y&tz=UTC&format=ics"+
+			"&t1=20210729T000000Z&t2=20210730T000000Z", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		body := rr.Body.String()
+		assert.NotContains(t, body, "BEGIN:VEVENT")
+		assert.Contains(t, body, "TZOFFSETFROM:+0000\r\nTZOFFSETTO:+0000\r\nTZNAME:UTC\r\n")
+	})
+}
+
+func TestCalendar_Transitions(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+
+	list, err := transitions(ctx, tz, t1, t2)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC),
+	}, utc(list))
+
+	list, err = transitions(ctx, time.UTC, t1, t2)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+	list, err = transitions(ctx, tz, t1, t1)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	// The search over a long range stops with the context
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = transitions(ctx, tz, t1, t1.AddDate(8000, 0, 0))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCalendar_Line(t *testing.T) {
+	var b bytes.Buffer
+	c := &calendar{w: &b}
+
+	// Folded at 75 octets, between the characters
+	c.line("SUMMARY", strings.Repeat("é", 50))
+	for _, l := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(l), maxLine)
+	}
+	unfolded := strings.ReplaceAll(b.String(), "\r\n ", "")
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 50)+"\r\n", unfolded)
+
+	assert.Equal(t, `a\;b\,c\\d\ne`, icsText.Replace("a;b,c\\d\ne"))
+	assert.Equal(t, "+0300", utcOffset(3*60*60))
+	assert.Equal(t, "-0330", utcOffset(-(3*60*60 + 30*60)))
+	assert.Equal(t, "+013415", utcOffset(60*60+34*60+15))
+}
+
+// utc returns the times in UTC.
+func utc(list []time.Time) []time.Time {
+	for i, t := range list {
+		list[i] = t.UTC()
+	}
+	return list
+}
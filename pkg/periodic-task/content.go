@@ -0,0 +1,181 @@
+package periodictask
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The media types of the timestamps of a response
+const (
+	mediaJSON   = "application/json"
+	mediaNDJSON = "application/x-ndjson"
+	mediaCSV    = "text/csv"
+	mediaText   = "text/plain"
+)
+
+// mediaFormats are the media types by the name of the format parameter
+var mediaFormats = map[string]string{
+	"json":   mediaJSON,
+	"v2":     mediaEnvelope,
+	"ndjson": mediaNDJSON,
+	"csv":    mediaCSV,
+	"text":   mediaText,
+	"ics":    mediaCalendar,
+}
+
+// mediaRanges are the media types of the Accept header, with their aliases
+// and wildcards
+var mediaRanges = map[string]string{
+	mediaJSON:            mediaJSON,
+	mediaEnvelope:        mediaEnvelope,
+	mediaNDJSON:          mediaNDJSON,
+	"application/ndjson": mediaNDJSON,
+	"application/jsonl":  mediaNDJSON,
+	mediaCSV:             mediaCSV,
+	mediaText:            mediaText,
+	mediaCalendar:        mediaCalendar,
+	"*/*":                mediaJSON,
+	"application/*":      mediaJSON,
+	"text/*":             mediaText,
+}
+
+// negotiate returns the media type of the response, which is the one of the
+// format parameter, or else the most preferred one of the Accept header, and
+// JSON by default. It reports false when the format parameter is unknown.
+func negotiate(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		media, ok := mediaFormats[format]
+		return media, ok
+	}
+
+	media, best := mediaJSON, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		params := strings.Split(part, ";")
+		m, ok := mediaRanges[strings.ToLower(strings.TrimSpace(params[0]))]
+		if !ok {
+			continue
+		}
+
+		// The quality is 1 by default, and 0 is not acceptable
+		q := 1.0
+		for _, p := range params[1:] {
+			if v := strings.TrimSpace(p); strings.HasPrefix(v, "q=") {
+				if f, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > best {
+			media, best = m, q
+		}
+	}
+	return media, true
+}
+
+// contentType returns the Content-Type header of a media type.
+func contentType(media string) string {
+	if media == "" {
+		media = mediaJSON
+	}
+	return media + "; charset=UTF-8"
+}
+
+// timestampEncoder writes the timestamps of a response body one by one, so
+// that they are streamed while they are generated.
+type timestampEncoder interface {
+	encode(w io.Writer, ts interface{}) error
+	// close ends the body after the last timestamp
+	close(w io.Writer) error
+}
+
+// newEncoder returns the encoder of the media type of an output, JSON by
+// default.
+func newEncoder(out output) timestampEncoder {
+	switch out.media {
+	case mediaEnvelope:
+		return &envelopeEncoder{envelope: out.envelope}
+	case mediaNDJSON:
+		return &lineEncoder{json: true}
+	case mediaCSV:
+		return &lineEncoder{header: "timestamp"}
+	case mediaText:
+		return &lineEncoder{}
+	default:
+		return &jsonEncoder{}
+	}
+}
+
+// jsonEncoder writes a JSON array, which is cut short by an error.
+type jsonEncoder struct {
+	started bool
+}
+
+func (e *jsonEncoder) encode(w io.Writer, ts interface{}) error {
+	sep := ","
+	if !e.started {
+		sep, e.started = "[", true
+	}
+	b, _ := json.Marshal(ts)
+	if _, err := io.WriteString(w, sep); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func (e *jsonEncoder) close(w io.Writer) error {
+	end := "]\n"
+	if !e.started {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
+
+// lineEncoder writes a timestamp per line, after an optional header line.
+// The timestamps are JSON values with json (NDJSON), and plain otherwise.
+type lineEncoder struct {
+	header  string
+	json    bool
+	started bool
+}
+
+func (e *lineEncoder) encode(w io.Writer, ts interface{}) error {
+	if err := e.start(w); err != nil {
+		return err
+	}
+
+	var line []byte
+	switch v := ts.(type) {
+	case string:
+		if e.json {
+			line, _ = json.Marshal(v)
+		} else {
+			line = []byte(v)
+		}
+	case int64:
+		line = strconv.AppendInt(nil, v, 10)
+	}
+	_, err := w.Write(append(line, '\n'))
+	return err
+}
+
+func (e *lineEncoder) close(w io.Writer) error {
+	return e.start(w)
+}
+
+// start writes the header line once, even without timestamps.
+func (e *lineEncoder) start(w io.Writer) error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	if e.header == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, e.header+"\n")
+	return err
+}
@@ -0,0 +1,126 @@
+package periodictask
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// mediaEnvelope is the media type of the v2 response, a JSON object with the
+// metadata of the query and the timestamps
+const mediaEnvelope = "application/vnd.periodic-task.v2+json"
+
+// envelope is the metadata of the v2 response, which wraps the timestamps.
+type envelope struct {
+	Period string `json:"period"`
+	TZ     string `json:"tz"`
+	// TZDataVersion is the version of the timezone database, empty when
+	// unknown
+	TZDataVersion string      `json:"tzdataVersion"`
+	T1            interface{} `json:"t1"`
+	T2            interface{} `json:"t2"`
+	Bounds        string      `json:"bounds"`
+	// At, DST, Anchor, Calendar and Adjust are the options that interpret
+	// the period, in the form of their query parameters
+	At       string `json:"at"`
+	DST      string `json:"dst"`
+	Anchor   string `json:"anchor"`
+	Calendar string `json:"calendar"`
+	Adjust   string `json:"adjust"`
+}
+
+// envelopeEncoder writes the timestamps in the v2 response object, and their
+// number after them, since they are streamed.
+type envelopeEncoder struct {
+	envelope *envelope
+	count    int
+}
+
+func (e *envelopeEncoder) encode(w io.Writer, ts interface{}) error {
+	sep := ","
+	if e.count == 0 {
+		if err := e.start(w); err != nil {
+			return err
+		}
+		sep = ""
+	}
+	e.count++
+
+	b, _ := json.Marshal(ts)
+	_, err := io.WriteString(w, sep+string(b))
+	return err
+}
+
+func (e *envelopeEncoder) close(w io.Writer) error {
+	if e.count == 0 {
+		if err := e.start(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "],\"count\":%d}\n", e.count)
+	return err
+}
+
+// start writes the metadata, and opens the array of the timestamps.
+func (e *envelopeEncoder) start(w io.Writer) error {
+	b, _ := json.Marshal(e.envelope)
+	_, err := io.WriteString(w, strings.TrimSuffix(string(b), "}")+`,"timestamps":[`)
+	return err
+}
+
+// zoneinfoDirs are the directories of the timezone database of the system,
+// where the time package looks for it
+var zoneinfoDirs = []string{
+	"/usr/share/zoneinfo/",
+	"/usr/share/lib/zoneinfo/",
+	"/usr/lib/locale/TZ/",
+}
+
+var tzdata struct {
+	once    sync.Once
+	version string
+}
+
+// tzdataVersion returns the version of the timezone database (e.g. 2024a),
+// from the tzdata.zi file of the ZONEINFO directory or of the system, or ""
+// when it is unknown.
+func tzdataVersion() string {
+	tzdata.once.Do(func() {
+		dirs := zoneinfoDirs
+		if dir := os.Getenv("ZONEINFO"); dir != "" {
+			dirs = append([]string{dir}, dirs...)
+		}
+		for _, dir := range dirs {
+			if v, ok := readTZDataVersion(filepath.Join(dir, "tzdata.zi")); ok {
+				tzdata.version = v
+				return
+			}
+		}
+	})
+	return tzdata.version
+}
+
+// readTZDataVersion reads the version of a tzdata.zi file, from its first
+// line "# version 2024a".
+func readTZDataVersion(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	v := strings.TrimPrefix(strings.TrimSpace(line), "# version ")
+	if v == strings.TrimSpace(line) || v == "" {
+		return "", false
+	}
+	return v, true
+}
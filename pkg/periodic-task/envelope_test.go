@@ -0,0 +1,35 @@
+package periodictask
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTZDataVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{content: "# version 2024a\n# ddeps backzone\n", expected: "2024a"},
+		{content: "# version 2023c", expected: "2023c"},
+		{content: "R d 1916 o - Jun 14 23s 1 S\n"},
+		{content: "# version \n"},
+		{content: ""},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "tzdata.zi")
+		assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+		v, ok := readTZDataVersion(path)
+		assert.Equal(t, tt.expected != "", ok, "case %d", i)
+		assert.Equal(t, tt.expected, v, "case %d", i)
+	}
+
+	_, ok := readTZDataVersion(filepath.Join(dir, "missing"))
+	assert.False(t, ok)
+}
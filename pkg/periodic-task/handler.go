@@ -1,9 +1,14 @@
 package periodictask
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"periodic-task/pkg/period"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -11,37 +16,261 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxNext is the maximum number of timestamps of the next endpoint
+const maxNext = 1000
+
+// maxSchedule is the maximum size of a posted schedule document
+const maxSchedule = 1 << 20
+
 var (
 	periodRequired      = "period required"
 	startPointRequired  = "start point required"
 	endPointRequired    = "end point required"
 	startAftertEndPoint = "start point should be before end point"
 	timezoneRequired    = "timezone required"
+	timestampRequired   = "timestamp required"
 )
 
 type PeriodHandler struct {
 	S Service
 
 	L *zap.SugaredLogger
+
+	// Formats are the additional timestamp formats of the requests by the
+	// name of the tf parameter, which take precedence over the built-in
+	// basic, rfc3339, epoch, epochms and local formats.
+	Formats map[string]TimestampFormat
 }
 
 // Router sets up all the routes for period service
 func (h *PeriodHandler) Router() chi.Router {
 	r := chi.NewRouter()
 
-	r.Get("/", h.ptlist)
+	r.Get("/ptlist", h.ptlist)
+	r.Get("/next", h.next)
+	r.Get("/prev", h.prev)
+	r.Get("/ptcount", h.ptcount)
+	r.Get("/ptmatch", h.ptmatch)
+	r.Get("/periods", h.periods)
+
+	// The period may be a schedule document in the request body
+	r.Post("/ptlist", h.ptlist)
+	r.Post("/next", h.next)
+	r.Post("/prev", h.prev)
+	r.Post("/ptcount", h.ptcount)
+	r.Post("/ptmatch", h.ptmatch)
 
 	return r
 }
 
 // ptlist retrieves the matching timestamps of a periodic task
 func (h *PeriodHandler) ptlist(w http.ResponseWriter, r *http.Request) {
-	// Get the period from url
-	p := r.URL.Query().Get("period")
-	if p == "" {
+	q, ok := h.parseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	t1, t2, ok := h.parseRange(w, r, &q)
+	if !ok {
+		return
+	}
+
+	// Get the optional page from url
+	pg, ok := h.parsePage(w, r, q, t1, t2)
+	if !ok {
+		return
+	}
+
+	// Get the media type of the response from url, or the Accept header
+	w.Header().Add("Vary", "Accept")
+	media, ok := negotiate(r)
+	if !ok {
+		h.L.Error(r.URL.Query().Get("format") + " is unknown response format")
+		httpError(w, http.StatusBadRequest, errUnknownMedia(r.URL.Query().Get("format")))
+		return
+	}
+	q.out.media = media
+	if media == mediaEnvelope {
+		q.out.envelope = &envelope{
+			Period:        q.period,
+			TZ:            q.tz.String(),
+			TZDataVersion: tzdataVersion(),
+			T1:            q.out.timestamp(t1),
+			T2:            q.out.timestamp(t2),
+			Bounds:        q.opts.Bounds.String(),
+			At:            q.opts.At.String(),
+			DST:           q.opts.DST.String(),
+			Anchor:        q.opts.Anchor.String(),
+			Calendar:      q.opts.Calendar,
+			Adjust:        q.opts.Adjust.String(),
+		}
+	}
+	if media == mediaCalendar {
+		if pg.limit > 0 {
+			h.L.Error("a page of a calendar is requested")
+			httpError(w, http.StatusBadRequest, errPageCalendar)
+			return
+		}
+		h.writeCalendar(w, r, q, t1, t2)
+		return
+	}
+
+	if pg.limit > 0 {
+		h.writePage(w, r, q, t1, t2, pg)
+		return
+	}
+
+	it, err := h.S.IterPTList(r.Context(), q.period, t1, t2, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	h.writeTimestamps(w, it, q.out)
+}
+
+// ptcount retrieves the number of matching timestamps of a periodic task
+func (h *PeriodHandler) ptcount(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.parseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	t1, t2, ok := h.parseRange(w, r, &q)
+	if !ok {
+		return
+	}
+
+	n, err := h.S.GetPTCount(r.Context(), q.period, t1, t2, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, countResponse{Count: n})
+}
+
+// ptmatch checks whether the time t is a matching timestamp of a periodic task
+func (h *PeriodHandler) ptmatch(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.parseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	// Get the t from url
+	t, ok := h.parseTimestamp(w, r, q, "t", timestampRequired)
+	if !ok {
+		return
+	}
+
+	match, err := h.S.GetPTMatch(r.Context(), q.period, t, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, matchResponse{Match: match})
+}
+
+// periods lists the registered periods
+func (h *PeriodHandler) periods(w http.ResponseWriter, r *http.Request) {
+	defs := h.S.GetPeriods()
+
+	list := make([]periodResponse, 0, len(defs))
+	for _, d := range defs {
+		list = append(list, periodResponse{Name: d.Name, Description: d.Description})
+	}
+	writeResponse(w, http.StatusOK, list)
+}
+
+// next retrieves the next matching timestamps of a periodic task after the
+// time t, now by default
+func (h *PeriodHandler) next(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.parseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	// Get the optional reference time from url
+	t, ok := h.parseReference(w, r, q)
+	if !ok {
+		return
+	}
+
+	// Get the optional number of timestamps from url
+	n := 1
+	if sn := r.URL.Query().Get("n"); sn != "" {
+		var err error
+		if n, err = strconv.Atoi(sn); err != nil || n < 1 || n > maxNext {
+			h.L.Error(sn + " is invalid number of timestamps")
+			httpError(w, http.StatusBadRequest, errInvalidNumber(sn))
+			return
+		}
+	}
+
+	list, err := h.S.GetNext(r.Context(), q.period, t, n, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, q.out.timestamps(list))
+}
+
+// prev retrieves the previous matching timestamp of a periodic task before
+// the time t, now by default
+func (h *PeriodHandler) prev(w http.ResponseWriter, r *http.Request) {
+	q, ok := h.parseQuery(w, r)
+	if !ok {
+		return
+	}
+
+	// Get the optional reference time from url
+	t, ok := h.parseReference(w, r, q)
+	if !ok {
+		return
+	}
+
+	list, err := h.S.GetPrev(r.Context(), q.period, t, q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, q.out.timestamps(list))
+}
+
+// query holds the query parameters that describe a periodic task
+type query struct {
+	period string
+	tz     *time.Location
+	// tf is the timestamp format named tfName, which is empty by default
+	tf     TimestampFormat
+	tfName string
+	out    output
+	opts   period.Options
+}
+
+// parseQuery gets the period, the timezone and the options from url. It
+// writes the error response and reports false when one is invalid.
+func (h *PeriodHandler) parseQuery(w http.ResponseWriter, r *http.Request) (query, bool) {
+	var q query
+
+	// Get the period from url, or the schedule document from the body
+	q.period = r.URL.Query().Get("period")
+	if q.period == "" && r.Method == http.MethodPost {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSchedule))
+		if err != nil {
+			h.L.Error("could not read the schedule: ", err)
+			httpError(w, http.StatusBadRequest, errInvalidSchedule(err))
+			return q, false
+		}
+		q.period = strings.TrimSpace(string(body))
+	}
+	if q.period == "" {
 		h.L.Error("no period found")
 		httpError(w, http.StatusBadRequest, periodRequired)
-		return
+		return q, false
 	}
 
 	// Get the timezone from url
@@ -49,7 +278,7 @@ func (h *PeriodHandler) ptlist(w http.ResponseWriter, r *http.Request) {
 	if stz == "" {
 		h.L.Error("no timezone found")
 		httpError(w, http.StatusBadRequest, timezoneRequired)
-		return
+		return q, false
 	}
 
 	// Verify the requested timezone
@@ -57,74 +286,325 @@ func (h *PeriodHandler) ptlist(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.L.Error(stz + " is invalid timezone")
 		httpError(w, http.StatusBadRequest, errInvalidTimezone(stz))
-		return
+		return q, false
 	}
+	q.tz = tz
 
-	// Get the t1 from url
-	st1 := r.URL.Query().Get("t1")
-	if st1 == "" {
-		h.L.Error("no start point found")
-		httpError(w, http.StatusBadRequest, startPointRequired)
-		return
+	// Get the optional timestamp format from url
+	q.tfName = r.URL.Query().Get("tf")
+	tf, ok := h.timestampFormat(q.tfName)
+	if !ok {
+		h.L.Error(q.tfName + " is unknown timestamp format")
+		httpError(w, http.StatusBadRequest, errUnknownFormat(q.tfName))
+		return q, false
 	}
+	q.tf = tf
 
-	// Convert t1 as time
-	t1, err := time.Parse(period.SUPPORTEDFORMAT, st1)
+	// Get the optional output format and zone from url
+	out, err := parseOutput(r.URL.Query().Get("out"), r.URL.Query().Get("zone"), tz)
 	if err != nil {
-		h.L.Error("no supported format for " + st1)
-		httpError(w, http.StatusBadRequest, errNoSupportedFormat(st1))
-		return
+		h.L.Error(err.Error())
+		httpError(w, http.StatusBadRequest, err.Error())
+		return q, false
 	}
+	q.out = out
 
-	// Get the t2 from url
-	st2 := r.URL.Query().Get("t2")
-	if st2 == "" {
-		h.L.Error("no end point found")
-		httpError(w, http.StatusBadRequest, endPointRequired)
-		return
+	// Get the optional invocation point from url
+	at, err := period.ParseInvocation(r.URL.Query().Get("at"))
+	if err != nil {
+		h.L.Error(err.Error())
+		httpError(w, http.StatusBadRequest, errInvalidInvocation(err))
+		return q, false
 	}
 
-	// Convert t2 as time
-	t2, err := time.Parse(period.SUPPORTEDFORMAT, st2)
+	// Get the optional business day convention from url
+	adjust, err := period.ParseConvention(r.URL.Query().Get("adjust"))
 	if err != nil {
-		h.L.Error("no supported format for " + st2)
-		httpError(w, http.StatusBadRequest, errNoSupportedFormat(st2))
-		return
+		h.L.Error(err.Error())
+		httpError(w, http.StatusBadRequest, err.Error())
+		return q, false
+	}
+
+	// A calendar only tells the non-working days of a business day convention
+	calendar := r.URL.Query().Get("calendar")
+	if calendar != "" && adjust == period.NoAdjustment {
+		h.L.Error("calendar ", calendar, " is requested without a business day convention")
+		httpError(w, http.StatusBadRequest, errCalendarAdjust)
+		return q, false
+	}
+
+	// Get the optional daylight saving time policy from url
+	dst, err := period.ParseDST(r.URL.Query().Get("dst"))
+	if err != nil {
+		h.L.Error(err.Error())
+		httpError(w, http.StatusBadRequest, err.Error())
+		return q, false
+	}
+
+	// Get the optional anchor from url
+	anchor, err := period.ParseAnchor(r.URL.Query().Get("anchor"))
+	if err != nil {
+		h.L.Error(err.Error())
+		httpError(w, http.StatusBadRequest, err.Error())
+		return q, false
+	}
+
+	q.opts = period.Options{
+		At:       at,
+		Calendar: calendar,
+		Adjust:   adjust,
+		DST:      dst,
+		Anchor:   anchor,
+	}
+	return q, true
+}
+
+// parseTimestamp gets a required timestamp from url, in the timestamp format
+// of q. It writes the error response and reports false when it is missing or
+// invalid.
+func (h *PeriodHandler) parseTimestamp(w http.ResponseWriter, r *http.Request, q query,
+	name, required string) (time.Time, bool) {
+	st := r.URL.Query().Get(name)
+	if st == "" {
+		h.L.Error("no " + name + " found")
+		httpError(w, http.StatusBadRequest, required)
+		return time.Time{}, false
+	}
+
+	// Convert it as time
+	t, err := q.tf(st, q.tz)
+	if err != nil {
+		h.L.Error("no supported format for " + st)
+		if q.tfName != "" && q.tfName != autoFormat {
+			httpError(w, http.StatusBadRequest, errInvalidFormat(st, q.tfName))
+		} else {
+			httpError(w, http.StatusBadRequest, errNoSupportedFormat(st))
+		}
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseRange gets the required range t1 to t2 from url, and its optional
+// bounds into the options of q. It writes the error response and reports
+// false when one is missing or invalid.
+func (h *PeriodHandler) parseRange(w http.ResponseWriter, r *http.Request,
+	q *query) (time.Time, time.Time, bool) {
+	// Get the t1 from url
+	t1, ok := h.parseTimestamp(w, r, *q, "t1", startPointRequired)
+	if !ok {
+		return t1, t1, false
+	}
+
+	// Get the t2 from url
+	t2, ok := h.parseTimestamp(w, r, *q, "t2", endPointRequired)
+	if !ok {
+		return t1, t2, false
 	}
 
 	// t1 should be before t2
 	if t1.After(t2) {
 		h.L.Error("t1 is after t2")
 		httpError(w, http.StatusBadRequest, startAftertEndPoint)
-		return
+		return t1, t2, false
 	}
 
-	ptlist, err := h.S.GetPTList(r.Context(), p, t1, t2, tz)
+	// Get the optional bounds from url
+	bounds, err := period.ParseBounds(r.URL.Query().Get("bounds"))
 	if err != nil {
+		h.L.Error(err.Error())
 		httpError(w, http.StatusBadRequest, err.Error())
+		return t1, t2, false
+	}
+	q.opts.Bounds = bounds
+	return t1, t2, true
+}
+
+// parseReference gets the optional reference time t from url, which is now
+// by default.
+func (h *PeriodHandler) parseReference(w http.ResponseWriter, r *http.Request,
+	q query) (time.Time, bool) {
+	if r.URL.Query().Get("t") == "" {
+		return time.Now().UTC(), true
+	}
+	return h.parseTimestamp(w, r, q, "t", "")
+}
+
+// writeTimestamps streams the timestamps of an iterator in the media type of
+// the output, a JSON array by default, writing every timestamp as soon as it
+// is generated.
+func (h *PeriodHandler) writeTimestamps(w http.ResponseWriter, it period.Iterator, out output) {
+	// An error before the first timestamp is still reported as such, while
+	// a later one can only cut the body short
+	more := it.Next()
+	if err := it.Err(); err != nil {
+		h.iterationError(w, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(ptlist); err != nil {
+	w.Header().Set("Content-Type", contentType(out.media))
+	enc := newEncoder(out)
+	for ; more; more = it.Next() {
+		if err := enc.encode(w, out.timestamp(it.Time())); err != nil {
+			h.L.Error(err.Error())
+			return
+		}
+	}
+	if err := it.Err(); err != nil {
+		h.L.Error("the timestamps are cut short: ", err)
+		return
+	}
+
+	if err := enc.close(w); err != nil {
 		h.L.Error(err.Error())
-		httpError(w, http.StatusInternalServerError, err.Error())
 	}
 }
 
 // errNoSupportedFormat is used when an invocation point (timestamp) could not be parsed
 func errNoSupportedFormat(t string) string {
 	return t + " is not a supported format. A valid timestamp format is " +
-		period.SUPPORTEDFORMAT
+		period.SUPPORTEDFORMAT + ", RFC 3339 (2006-01-02T15:04:05+02:00), Unix epoch " +
+		"seconds or milliseconds, or a local time (2006-01-02T15:04:05)"
+}
+
+// errUnknownMedia is used when the requested response format is not supported
+func errUnknownMedia(format string) string {
+	return format + " is unknown response format. It should be one of json, v2, ndjson, csv, text or ics"
+}
+
+// errInvalidFormat is used when a timestamp is not in the requested format
+func errInvalidFormat(t, tf string) string {
+	return t + " is not a valid " + tf + " timestamp"
+}
+
+// errUnknownFormat is used when the requested timestamp format is not supported
+func errUnknownFormat(tf string) string {
+	return tf + " is unknown timestamp format. It should be one of auto, basic, " +
+		"rfc3339, epoch, epochms or local"
 }
 
+// errInvalidInvocation is used when the invocation point could not be parsed
+func errInvalidInvocation(err error) string {
+	return "invalid invocation point: " + err.Error()
+}
+
+// errInvalidSchedule is used when the schedule document could not be read
+func errInvalidSchedule(err error) string {
+	return "invalid schedule: " + err.Error()
+}
+
+// errInvalidNumber is used when the number of timestamps is invalid
+func errInvalidNumber(n string) string {
+	return n + " is not a valid number of timestamps. It should be from 1 to " +
+		strconv.Itoa(maxNext)
+}
+
+// hintPagination is the hint of a list that exceeds a limit
+var hintPagination = "request the timestamps in pages, with limit and the cursor of the " +
+	"next link, or in a shorter range"
+
+// errDeadline is used when the timestamps are not generated before the
+// timeout of the server
+var errDeadline = "the timestamps were not generated within the timeout of the server"
+
+// errCanceled is used when the request is canceled while its timestamps are
+// generated, e.g. the client disconnected
+var errCanceled = "the request was canceled before its timestamps were generated"
+
+// errCalendarAdjust is used when a calendar is requested without a business
+// day convention, which would leave the timestamps as they are
+var errCalendarAdjust = "a calendar needs a business day convention (adjust) other than none"
+
 // errInvalidTimezone is used when the timezone is invalid
 func errInvalidTimezone(tz string) string {
 	return tz + " is not a valid timezone."
 }
 
+// periodResponse is the response body of a registered period
+type periodResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// countResponse is the response body of the number of matching timestamps
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+// matchResponse is the response body of whether a timestamp matches
+type matchResponse struct {
+	Match bool `json:"match"`
+}
+
 type responseError struct {
 	Status string `json:"status"`
 	Desc   string `json:"desc"`
+	// Limit and Hint are the exceeded limit of a list, and how to get it
+	Limit *limitResponse `json:"limit,omitempty"`
+	Hint  string         `json:"hint,omitempty"`
+}
+
+// limitResponse is the exceeded limit of a list, either the maximum number
+// of timestamps or the maximum range of the period
+type limitResponse struct {
+	MaxCount int    `json:"maxCount,omitempty"`
+	MaxRange string `json:"maxRange,omitempty"`
+}
+
+// serviceError writes the error response of a service error, which is a bad
+// request unless a list exceeds the maximum count, and then is too large, or
+// the generation of the timestamps is stopped by the context of the request.
+func (h *PeriodHandler) serviceError(w http.ResponseWriter, err error) {
+	if contextError(w, err) {
+		return
+	}
+
+	var le *limitError
+	if !errors.As(err, &le) {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, limit := http.StatusRequestEntityTooLarge, &limitResponse{MaxCount: le.maxCount}
+	if errors.Is(err, errRangeTooLong) {
+		status, limit = http.StatusBadRequest, &limitResponse{MaxRange: le.maxRange.String()}
+	}
+	writeResponse(w, status, responseError{
+		Status: "error",
+		Desc:   err.Error(),
+		Limit:  limit,
+		Hint:   hintPagination,
+	})
+}
+
+// iterationError writes the error response of an iteration that stopped
+// before its first timestamps, which is an internal error unless the context
+// of the request stopped it.
+func (h *PeriodHandler) iterationError(w http.ResponseWriter, err error) {
+	h.L.Error(err.Error())
+	if contextError(w, err) {
+		return
+	}
+	httpError(w, http.StatusInternalServerError, err.Error())
+}
+
+// contextError writes the error response of a generation that the context of
+// the request stopped, and reports whether it did. The deadline of the server
+// is a gateway timeout, while a canceled request is unavailable.
+func contextError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeResponse(w, http.StatusGatewayTimeout, responseError{
+			Status: "error",
+			Desc:   errDeadline,
+			Hint:   hintPagination,
+		})
+	case errors.Is(err, context.Canceled):
+		httpError(w, http.StatusServiceUnavailable, errCanceled)
+	default:
+		return false
+	}
+	return true
 }
 
 func httpError(w http.ResponseWriter, status int, desc string) {
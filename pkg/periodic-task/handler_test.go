@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"periodic-task/pkg/period"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,11 +26,116 @@ type mockPeriodService struct {
 
 func (mps *mockPeriodService) GetPTList(
 	ctx context.Context, period string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
 ) ([]string, error) {
-	args := mps.Called(ctx, period, t1, t2, tz)
+	args := mps.Called(ctx, period, t1, t2, tz, opts)
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (mps *mockPeriodService) GetPTTimes(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	args := mps.Called(ctx, p, t1, t2, tz, opts)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (mps *mockPeriodService) IterPTList(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) (period.Iterator, error) {
+	args := mps.Called(ctx, p, t1, t2, tz, opts)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	it := &sliceIterator{}
+	for _, s := range args.Get(0).([]string) {
+		t, _ := time.Parse(period.SUPPORTEDFORMAT, s)
+		it.list = append(it.list, t)
+	}
+	return it, nil
+}
+
+func (mps *mockPeriodService) GetPTPage(
+	ctx context.Context, p string, t1, t2, after time.Time, limit int, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, bool, error) {
+	args := mps.Called(ctx, p, t1, t2, after, limit, tz, opts)
+	return args.Get(0).([]time.Time), args.Bool(1), args.Error(2)
+}
+
+func (mps *mockPeriodService) GetNext(
+	ctx context.Context, p string, after time.Time, n int, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	args := mps.Called(ctx, p, after, n, tz, opts)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (mps *mockPeriodService) GetPrev(
+	ctx context.Context, p string, before time.Time, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	args := mps.Called(ctx, p, before, tz, opts)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (mps *mockPeriodService) GetPTCount(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) (int, error) {
+	args := mps.Called(ctx, p, t1, t2, tz, opts)
+	return args.Int(0), args.Error(1)
+}
+
+func (mps *mockPeriodService) GetPTMatch(
+	ctx context.Context, p string, t time.Time, tz *time.Location,
+	opts period.Options,
+) (bool, error) {
+	args := mps.Called(ctx, p, t, tz, opts)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mps *mockPeriodService) GetPTRule(
+	ctx context.Context, p string, tz *time.Location, opts period.Options,
+) (string, bool, error) {
+	args := mps.Called(ctx, p, tz, opts)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (mps *mockPeriodService) GetPeriods() []period.Definition {
+	args := mps.Called()
+	return args.Get(0).([]period.Definition)
+}
+
+// sliceIterator iterates over a list of timestamps, and then fails with err
+type sliceIterator struct {
+	list []time.Time
+	err  error
+	i    int
+	done bool
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.i >= len(it.list) {
+		it.done = true
+		return false
+	}
+	it.i++
+	return true
+}
+
+func (it *sliceIterator) Time() time.Time {
+	return it.list[it.i-1]
+}
+
+func (it *sliceIterator) Err() error {
+	if it.done {
+		return it.err
+	}
+	return nil
+}
+
 func TestPeriodHandler_PTList(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 
@@ -61,7 +169,7 @@ func TestPeriodHandler_PTList(t *testing.T) {
 		tz, _ := time.LoadLocation("Europe/Athens")
 		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T000000Z")
 		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T040000Z")
-		mockService.On("GetPTList", mock.Anything, "1h", t1, t2, tz).
+		mockService.On("IterPTList", mock.Anything, "1h", t1, t2, tz, period.Options{}).
 			Return([]string{
 				"20210729T000000Z",
 				"20210729T010000Z",
@@ -95,6 +203,213 @@ func TestPeriodHandler_PTList(t *testing.T) {
 		}
 	})
 
+	t.Run("InvocationPoint", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210101T000000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210401T000000Z")
+		opts := period.Options{At: period.Invocation{
+			Kind:  period.OffsetInvocation,
+			Day:   15,
+			Clock: 9*time.Hour + 30*time.Minute,
+		}}
+		mockService.On("IterPTList", mock.Anything, "1mo", t1, t2, tz, opts).
+			Return([]string{
+				"20210115T073000Z",
+				"20210215T073000Z",
+				"20210315T073000Z",
+			}, nil)
+
+		resp, err := makeRequest("GET", "/?period=1mo&t1=20210101T000000Z"+
+			"&t2=20210401T000000Z&tz=Europe/Athens&at=day%2015%20at%2009:30", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Len(t, ptlist, 3, "Expected number of timestamps")
+	})
+
+	t.Run("InvalidInvocationPoint", func(t *testing.T) {
+		resp, err := makeRequest("GET", "/?period=1mo&t1=20210101T000000Z"+
+			"&t2=20210401T000000Z&tz=Europe/Athens&at=middle", nil)
+		assert.NoError(t, err, "Expected no error")
+
+		var errorMsg responseError
+		err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "invalid invocation point")
+	})
+
+	t.Run("BusinessDayAdjustment", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210501T000000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210601T000000Z")
+		opts := period.Options{Calendar: "GR", Adjust: period.ModifiedFollowing}
+		mockService.On("IterPTList", mock.Anything, "1mo", t1, t2, tz, opts).
+			Return([]string{"20210530T210000Z"}, nil)
+
+		resp, err := makeRequest("GET", "/?period=1mo&t1=20210501T000000Z"+
+			"&t2=20210601T000000Z&tz=Europe/Athens&calendar=GR&adjust=modified-following", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"20210530T210000Z"}, ptlist)
+	})
+
+	t.Run("InvalidBusinessDayConvention", func(t *testing.T) {
+		resp, err := makeRequest("GET", "/?period=1mo&t1=20210101T000000Z"+
+			"&t2=20210401T000000Z&tz=Europe/Athens&adjust=nearest", nil)
+		assert.NoError(t, err, "Expected no error")
+
+		var errorMsg responseError
+		err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "business day convention")
+	})
+
+	t.Run("EncodedRRule", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210101T000000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210301T000000Z")
+		mockService.On("IterPTList", mock.Anything, "FREQ=MONTHLY;BYDAY=-1FR", t1, t2, tz, period.Options{}).
+			Return([]string{"20210128T220000Z", "20210225T220000Z"}, nil)
+
+		resp, err := makeRequest("GET", "/?period=FREQ=MONTHLY%3BBYDAY=-1FR&t1=20210101T000000Z"+
+			"&t2=20210301T000000Z&tz=Europe/Athens", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"20210128T220000Z", "20210225T220000Z"}, ptlist)
+	})
+
+	t.Run("CalendarWithoutConvention", func(t *testing.T) {
+		for _, query := range []string{"&calendar=GR", "&calendar=GR&adjust=none"} {
+			resp, err := makeRequest("GET", "/?period=1mo&t1=20210101T000000Z"+
+				"&t2=20210401T000000Z&tz=Europe/Athens"+query, nil)
+			assert.NoError(t, err, "Expected no error")
+
+			var errorMsg responseError
+			err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+			assert.NoError(t, err, "Expected no error while decoding JSON")
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+			assert.Contains(t, errorMsg.Desc, "business day convention")
+		}
+	})
+
+	t.Run("DSTPolicy", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20211030T230000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20211031T030000Z")
+		opts := period.Options{DST: period.DST{Stepping: period.WallStepping, Fold: period.BothFolds}}
+		mockService.On("IterPTList", mock.Anything, "1h", t1, t2, tz, opts).
+			Return([]string{
+				"20211030T230000Z",
+				"20211031T000000Z",
+				"20211031T010000Z",
+				"20211031T020000Z",
+			}, nil)
+
+		resp, err := makeRequest("GET", "/?period=1h&t1=20211030T230000Z"+
+			"&t2=20211031T030000Z&tz=Europe/Athens&dst=wall,both", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Len(t, ptlist, 4, "Expected number of timestamps")
+	})
+
+	t.Run("InvalidDSTPolicy", func(t *testing.T) {
+		resp, err := makeRequest("GET", "/?period=1h&t1=20211030T230000Z"+
+			"&t2=20211031T030000Z&tz=Europe/Athens&dst=never", nil)
+		assert.NoError(t, err, "Expected no error")
+
+		var errorMsg responseError
+		err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "daylight saving time policy")
+	})
+
+	t.Run("Anchor", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T200000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210715T000000Z")
+		anchor, _ := period.ParseAnchor("2020-01-01T00:15")
+		mockService.On("IterPTList", mock.Anything, "90m", t1, t2, tz,
+			period.Options{Anchor: anchor}).
+			Return([]string{"20210714T204500Z", "20210714T221500Z", "20210714T234500Z"}, nil)
+
+		resp, err := makeRequest("GET", "/?period=90m&t1=20210714T200000Z"+
+			"&t2=20210715T000000Z&tz=Europe/Athens&anchor=2020-01-01T00:15", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Len(t, ptlist, 3, "Expected number of timestamps")
+	})
+
+	t.Run("InvalidAnchor", func(t *testing.T) {
+		resp, err := makeRequest("GET", "/?period=90m&t1=20210714T200000Z"+
+			"&t2=20210715T000000Z&tz=Europe/Athens&anchor=yesterday", nil)
+		assert.NoError(t, err, "Expected no error")
+
+		var errorMsg responseError
+		err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "not a valid anchor")
+	})
+
+	t.Run("Bounds", func(t *testing.T) {
+		tz, _ := time.LoadLocation("Europe/Athens")
+		t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T000000Z")
+		t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T030000Z")
+		mockService.On("IterPTList", mock.Anything, "1h", t1, t2, tz,
+			period.Options{Bounds: period.OpenClosed}).
+			Return([]string{"20210714T010000Z", "20210714T020000Z", "20210714T030000Z"}, nil)
+
+		resp, err := makeRequest("GET", "/?period=1h&t1=20210714T000000Z"+
+			"&t2=20210714T030000Z&tz=Europe/Athens&bounds=(%5D", nil)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err = json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, "20210714T030000Z", ptlist[len(ptlist)-1], "Expected t2 to be included")
+	})
+
+	t.Run("InvalidBounds", func(t *testing.T) {
+		resp, err := makeRequest("GET", "/?period=1h&t1=20210714T000000Z"+
+			"&t2=20210714T030000Z&tz=Europe/Athens&bounds=()", nil)
+		assert.NoError(t, err, "Expected no error")
+
+		var errorMsg responseError
+		err = json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "not valid bounds")
+	})
+
 	t.Run("MissingPeriod", func(t *testing.T) {
 		// Make a request with missing period query parameter
 		resp, err := makeRequest("GET",
@@ -215,3 +530,643 @@ func TestPeriodHandler_PTList(t *testing.T) {
 		assert.Equal(t, timezoneRequired, errorMsg.Desc)
 	})
 }
+
+func TestPeriodHandler_WriteTimestamps(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ph := &PeriodHandler{L: logger.Sugar()}
+
+	t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T000000Z")
+	t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T010000Z")
+
+	t.Run("JSONArray", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ph.writeTimestamps(rr, &sliceIterator{list: []time.Time{t1, t2}}, output{})
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "[\"20210729T000000Z\",\"20210729T010000Z\"]\n", rr.Body.String())
+	})
+
+	t.Run("EmptyArray", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ph.writeTimestamps(rr, &sliceIterator{}, output{})
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "[]\n", rr.Body.String())
+	})
+
+	t.Run("ErrorBeforeFirstTimestamp", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+			desc   string
+		}{
+			{errors.New("generator failed"), http.StatusInternalServerError, "generator failed"},
+			{context.Canceled, http.StatusServiceUnavailable, errCanceled},
+			{fmt.Errorf("count: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, errDeadline},
+		}
+		for _, tt := range tests {
+			rr := httptest.NewRecorder()
+			ph.writeTimestamps(rr, &sliceIterator{err: tt.err}, output{})
+
+			var errorMsg responseError
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errorMsg))
+			assert.Equal(t, tt.status, rr.Code, tt.err.Error())
+			assert.Equal(t, tt.desc, errorMsg.Desc)
+		}
+	})
+
+	t.Run("ErrorCutsArrayShort", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ph.writeTimestamps(rr, &sliceIterator{list: []time.Time{t1}, err: context.Canceled}, output{})
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "[\"20210729T000000Z\"", rr.Body.String())
+	})
+
+	t.Run("EpochMilliseconds", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		out, _ := parseOutput("epochms", "", time.UTC)
+		ph.writeTimestamps(rr, &sliceIterator{list: []time.Time{t1, t2}}, out)
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "[1627516800000,1627520400000]\n", rr.Body.String())
+	})
+}
+
+func TestPeriodHandler_ContentNegotiation(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockService := new(mockPeriodService)
+	ph := &PeriodHandler{
+		S: mockService,
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T000000Z")
+	t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210729T020000Z")
+	mockService.On("IterPTList", mock.Anything, "1h", t1, t2, tz, period.Options{}).
+		Return([]string{"20210729T000000Z", "20210729T010000Z"}, nil)
+	anchor, _ := period.ParseAnchor("2021-07-01T00:15")
+	opts := period.Options{
+		At:       period.Invocation{Kind: period.StartInvocation},
+		Calendar: "GR",
+		Adjust:   period.Following,
+		DST:      period.DST{Stepping: period.ElapsedStepping, Gap: period.SkipGap},
+		Anchor:   anchor,
+	}
+	mockService.On("IterPTList", mock.Anything, "1h", t1, t2, tz, opts).
+		Return([]string{"20210729T001500Z", "20210729T011500Z"}, nil)
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "JSON by default",
+			contentType: "application/json; charset=UTF-8",
+			body:        "[\"20210729T000000Z\",\"20210729T010000Z\"]\n",
+		},
+		{
+			name:        "CSV",
+			accept:      "text/csv",
+			contentType: "text/csv; charset=UTF-8",
+			body:        "timestamp\n20210729T000000Z\n20210729T010000Z\n",
+		},
+		{
+			name:        "NDJSON",
+			accept:      "application/x-ndjson",
+			contentType: "application/x-ndjson; charset=UTF-8",
+			body:        "\"20210729T000000Z\"\n\"20210729T010000Z\"\n",
+		},
+		{
+			name:        "Plain text by preference",
+			accept:      "text/csv;q=0.5, text/plain, application/json;q=0.9",
+			contentType: "text/plain; charset=UTF-8",
+			body:        "20210729T000000Z\n20210729T010000Z\n",
+		},
+		{
+			name:        "Unsupported media type",
+			accept:      "image/png",
+			contentType: "application/json; charset=UTF-8",
+			body:        "[\"20210729T000000Z\",\"20210729T010000Z\"]\n",
+		},
+		{
+			name:        "Envelope",
+			query:       "&format=v2",
+			contentType: "application/vnd.periodic-task.v2+json; charset=UTF-8",
+			body: `{"period":"1h","tz":"Europe/Athens","tzdataVersion":"` + tzdataVersion() +
+				`","t1":"20210729T000000Z","t2":"20210729T020000Z","bounds":"[)",` +
+				`"at":"","dst":"shift,first","anchor":"","calendar":"","adjust":"none",` +
+				`"timestamps":["20210729T000000Z","20210729T010000Z"],"count":2}` + "\n",
+		},
+		{
+			name:        "Envelope with the options",
+			query:       "&format=v2&at=start&dst=elapsed,skip&anchor=2021-07-01T00:15&calendar=GR&adjust=following",
+			contentType: "application/vnd.periodic-task.v2+json; charset=UTF-8",
+			body: `{"period":"1h","tz":"Europe/Athens","tzdataVersion":"` + tzdataVersion() +
+				`","t1":"20210729T000000Z","t2":"20210729T020000Z","bounds":"[)",` +
+				`"at":"start","dst":"elapsed,skip,first","anchor":"20210701T001500","calendar":"GR",` +
+				`"adjust":"following","timestamps":["20210729T001500Z","20210729T011500Z"],"count":2}` + "\n",
+		},
+		{
+			name:        "Envelope by the Accept header",
+			query:       "&out=epoch",
+			accept:      "application/vnd.periodic-task.v2+json",
+			contentType: "application/vnd.periodic-task.v2+json; charset=UTF-8",
+			body: `{"period":"1h","tz":"Europe/Athens","tzdataVersion":"` + tzdataVersion() +
+				`","t1":1627516800,"t2":1627524000,"bounds":"[)",` +
+				`"at":"","dst":"shift,first","anchor":"","calendar":"","adjust":"none",` +
+				`"timestamps":[1627516800,1627520400],"count":2}` + "\n",
+		},
+		{
+			name:        "Format parameter over the Accept header",
+			query:       "&format=csv&out=epochms",
+			accept:      "application/json",
+			contentType: "text/csv; charset=UTF-8",
+			body:        "timestamp\n1627516800000\n1627520400000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/ptlist?period=1h&tz=Europe/Athens"+
+				"&t1=20210729T000000Z&t2=20210729T020000Z"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+			assert.Equal(t, tt.contentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, rr.Body.String())
+		})
+	}
+
+	t.Run("EmptyCSV", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ph.writeTimestamps(rr, &sliceIterator{}, output{media: mediaCSV})
+		assert.Equal(t, "timestamp\n", rr.Body.String())
+	})
+
+	t.Run("EmptyEnvelope", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ph.writeTimestamps(rr, &sliceIterator{}, output{media: mediaEnvelope,
+			envelope: &envelope{Period: "1y", TZ: "UTC"}})
+		assert.Equal(t, `{"period":"1y","tz":"UTC","tzdataVersion":"","t1":null,"t2":null,`+
+			`"bounds":"","at":"","dst":"","anchor":"","calendar":"","adjust":"",`+
+			`"timestamps":[],"count":0}`+"\n", rr.Body.String())
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ptlist?period=1h&tz=Europe/Athens"+
+			"&t1=20210729T000000Z&t2=20210729T020000Z&format=xml", nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var errorMsg responseError
+		err := json.NewDecoder(rr.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status Bad Request")
+		assert.Equal(t, errUnknownMedia("xml"), errorMsg.Desc)
+	})
+}
+
+func TestPeriodHandler_NextPrev(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockService := new(mockPeriodService)
+	ph := &PeriodHandler{
+		S: mockService,
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	makeRequest := func(path string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Result()
+	}
+
+	tz, _ := time.LoadLocation("Europe/Athens")
+	ref, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T204603Z")
+	next, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T210000Z")
+	prev, _ := time.Parse(period.SUPPORTEDFORMAT, "20210714T200000Z")
+
+	t.Run("Next", func(t *testing.T) {
+		mockService.On("GetNext", mock.Anything, "1h", ref, 2, tz, period.Options{}).
+			Return([]time.Time{next, next.Add(time.Hour)}, nil)
+
+		resp := makeRequest("/next?period=1h&tz=Europe/Athens&t=20210714T204603Z&n=2")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err := json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"20210714T210000Z", "20210714T220000Z"}, ptlist)
+	})
+
+	t.Run("NextInLocalTime", func(t *testing.T) {
+		resp := makeRequest("/next?period=1h&tz=Europe/Athens&t=20210714T204603Z&n=2" +
+			"&out=rfc3339&zone=local")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err := json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"2021-07-15T00:00:00+03:00", "2021-07-15T01:00:00+03:00"}, ptlist)
+	})
+
+	t.Run("InvalidOutput", func(t *testing.T) {
+		resp := makeRequest("/next?period=1h&tz=Europe/Athens&out=iso")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "unknown output format")
+	})
+
+	t.Run("NextFromNow", func(t *testing.T) {
+		mockService.On("GetNext", mock.Anything, "1d", mock.AnythingOfType("time.Time"), 1, tz,
+			period.Options{}).Return([]time.Time{next}, nil)
+
+		resp := makeRequest("/next?period=1d&tz=Europe/Athens")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+	})
+
+	t.Run("Prev", func(t *testing.T) {
+		mockService.On("GetPrev", mock.Anything, "1h", ref, tz, period.Options{}).
+			Return([]time.Time{prev}, nil)
+
+		resp := makeRequest("/prev?period=1h&tz=Europe/Athens&t=20210714T204603Z")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err := json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"20210714T200000Z"}, ptlist)
+	})
+
+	t.Run("NoPrev", func(t *testing.T) {
+		mockService.On("GetPrev", mock.Anything, "1y", ref, tz, period.Options{}).
+			Return([]time.Time(nil), nil)
+
+		resp := makeRequest("/prev?period=1y&tz=Europe/Athens&t=20210714T204603Z")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err := json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.NotNil(t, ptlist, "Expected an empty array")
+		assert.Empty(t, ptlist)
+	})
+
+	t.Run("NoPhase", func(t *testing.T) {
+		mockService.On("GetNext", mock.Anything, "PT1H", ref, 1, tz, period.Options{}).
+			Return([]time.Time(nil), period.ErrNoPhase)
+		mockService.On("GetPrev", mock.Anything, "PT1H", ref, tz, period.Options{}).
+			Return([]time.Time(nil), period.ErrNoPhase)
+
+		for _, path := range []string{"/next", "/prev"} {
+			resp := makeRequest(path + "?period=PT1H&tz=Europe/Athens&t=20210714T204603Z")
+
+			var errorMsg responseError
+			err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+			assert.NoError(t, err, "Expected no error while decoding JSON")
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+			assert.Equal(t, period.ErrNoPhase.Error(), errorMsg.Desc)
+		}
+	})
+
+	t.Run("InvalidNumber", func(t *testing.T) {
+		for _, n := range []string{"0", "-1", "x", "1001"} {
+			resp := makeRequest("/next?period=1h&tz=Europe/Athens&n=" + n)
+
+			var errorMsg responseError
+			err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+			assert.NoError(t, err, "Expected no error while decoding JSON")
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+			assert.Contains(t, errorMsg.Desc, "not a valid number of timestamps")
+		}
+	})
+
+	t.Run("InvalidReference", func(t *testing.T) {
+		resp := makeRequest("/prev?period=1h&tz=Europe/Athens&t=2021-07-14")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, errNoSupportedFormat("2021-07-14"), errorMsg.Desc)
+	})
+
+	t.Run("ReferenceFormats", func(t *testing.T) {
+		// 23:46:03 in Athens (UTC+3) is 20:46:03 UTC
+		for _, q := range []string{
+			"t=2021-07-14T23:46:03%2B03:00",
+			"t=2021-07-14T20:46:03Z&tf=rfc3339",
+			"t=1626295563",
+			"t=1626295563000&tf=epochms",
+			"t=2021-07-14T23:46:03&tf=local",
+		} {
+			resp := makeRequest("/prev?period=1h&tz=Europe/Athens&" + q)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK for %s", q)
+		}
+	})
+
+	t.Run("InvalidReferenceFormat", func(t *testing.T) {
+		resp := makeRequest("/prev?period=1h&tz=Europe/Athens&t=20210714T204603Z&tf=epoch")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, errInvalidFormat("20210714T204603Z", "epoch"), errorMsg.Desc)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		resp := makeRequest("/prev?period=1h&tz=Europe/Athens&t=20210714T204603Z&tf=iso")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, errUnknownFormat("iso"), errorMsg.Desc)
+	})
+
+	t.Run("MissingPeriod", func(t *testing.T) {
+		resp := makeRequest("/next?tz=Europe/Athens")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, periodRequired, errorMsg.Desc)
+	})
+}
+
+func TestPeriodHandler_CountMatch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockService := new(mockPeriodService)
+	ph := &PeriodHandler{
+		S: mockService,
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	makeRequest := func(path string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Result()
+	}
+
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210101T000000Z")
+	t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20220101T000000Z")
+
+	t.Run("Count", func(t *testing.T) {
+		mockService.On("GetPTCount", mock.Anything, "1mo", t1, t2, tz, period.Options{}).
+			Return(12, nil)
+
+		resp := makeRequest("/ptcount?period=1mo&tz=Europe/Athens&t1=20210101T000000Z&t2=20220101T000000Z")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var count countResponse
+		err := json.NewDecoder(resp.Body).Decode(&count)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, 12, count.Count)
+	})
+
+	t.Run("CountClosed", func(t *testing.T) {
+		mockService.On("GetPTCount", mock.Anything, "1mo", t1, t2, tz,
+			period.Options{Bounds: period.Closed}).
+			Return(13, nil)
+
+		resp := makeRequest("/ptcount?period=1mo&tz=Europe/Athens&t1=20210101T000000Z" +
+			"&t2=20220101T000000Z&bounds=closed")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var count countResponse
+		err := json.NewDecoder(resp.Body).Decode(&count)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, 13, count.Count)
+	})
+
+	t.Run("CountStartAfterEnd", func(t *testing.T) {
+		resp := makeRequest("/ptcount?period=1mo&tz=Europe/Athens&t1=20220101T000000Z&t2=20210101T000000Z")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, startAftertEndPoint, errorMsg.Desc)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		mockService.On("GetPTMatch", mock.Anything, "1y", t1, tz, period.Options{}).
+			Return(true, nil)
+
+		resp := makeRequest("/ptmatch?period=1y&tz=Europe/Athens&t=20210101T000000Z")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var match matchResponse
+		err := json.NewDecoder(resp.Body).Decode(&match)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.True(t, match.Match)
+	})
+
+	t.Run("MatchWithoutTimestamp", func(t *testing.T) {
+		resp := makeRequest("/ptmatch?period=1y&tz=Europe/Athens")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, timestampRequired, errorMsg.Desc)
+	})
+}
+
+func TestPeriodHandler_Schedule(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockService := new(mockPeriodService)
+	ph := &PeriodHandler{
+		S: mockService,
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	makeRequest := func(path, body string) *http.Response {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Result()
+	}
+
+	tz, _ := time.LoadLocation("Europe/Athens")
+	t1, _ := time.Parse(period.SUPPORTEDFORMAT, "20210730T000000Z")
+	t2, _ := time.Parse(period.SUPPORTEDFORMAT, "20210730T020000Z")
+	schedule := `{"union": [{"period": "1h"}, {"period": "0 9 * * *"}]}`
+
+	t.Run("PostedSchedule", func(t *testing.T) {
+		mockService.On("IterPTList", mock.Anything, schedule, t1, t2, tz, period.Options{}).
+			Return([]string{"20210730T000000Z", "20210730T010000Z"}, nil)
+
+		resp := makeRequest("/ptlist?tz=Europe/Athens&t1=20210730T000000Z&t2=20210730T020000Z",
+			"\n"+schedule+"\n")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+
+		var ptlist []string
+		err := json.NewDecoder(resp.Body).Decode(&ptlist)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+		assert.Equal(t, []string{"20210730T000000Z", "20210730T010000Z"}, ptlist)
+	})
+
+	t.Run("EmptyBody", func(t *testing.T) {
+		resp := makeRequest("/ptlist?tz=Europe/Athens&t1=20210730T000000Z&t2=20210730T020000Z", "")
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Equal(t, periodRequired, errorMsg.Desc)
+	})
+
+	t.Run("TooLargeSchedule", func(t *testing.T) {
+		body := strings.Repeat(" ", maxSchedule+1)
+		resp := makeRequest("/ptcount?tz=Europe/Athens&t1=20210730T000000Z&t2=20210730T020000Z", body)
+
+		var errorMsg responseError
+		err := json.NewDecoder(resp.Body).Decode(&errorMsg)
+		assert.NoError(t, err, "Expected no error while decoding JSON")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status Bad Request")
+		assert.Contains(t, errorMsg.Desc, "invalid schedule")
+	})
+}
+
+func TestPeriodHandler_Deadline(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ph := &PeriodHandler{
+		S: NewService(logger.Sugar(), nil, Limits{MaxCount: 100}),
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	// The days at midnight never match at noon, so every generation searches
+	// the whole range unless the context of the request stops it
+	schedule := `{"intersect": [{"period": "1d"}, {"period": "0 12 * * *"}]}`
+	query := "?tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z&t=00010101T000000Z"
+
+	serve := func(ctx context.Context, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(schedule)).WithContext(ctx)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("DeadlineExceeded", func(t *testing.T) {
+		for _, path := range []string{
+			"/ptlist" + query,
+			"/ptlist" + query + "&limit=10",
+			"/ptlist" + query + "&format=ics",
+			"/ptlist" + query + "&format=v2",
+			"/ptcount" + query,
+			"/next" + query,
+			"/prev" + strings.Replace(query, "&t=00010101", "&t=99991231", 1),
+		} {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			start := time.Now()
+			rr := serve(ctx, path)
+			cancel()
+
+			var errorMsg responseError
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errorMsg), path)
+			assert.Equal(t, http.StatusGatewayTimeout, rr.Code, path)
+			assert.Equal(t, errDeadline, errorMsg.Desc, path)
+			assert.Equal(t, hintPagination, errorMsg.Hint, path)
+			assert.Less(t, time.Since(start), time.Second, "Expected %s to stop early", path)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		// The client disconnects while the handler is generating, which stops
+		// its goroutine
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- serve(ctx, "/ptcount"+query)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		select {
+		case rr := <-done:
+			var errorMsg responseError
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errorMsg))
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code, "Expected status Service Unavailable")
+			assert.Equal(t, errCanceled, errorMsg.Desc)
+		case <-time.After(time.Second):
+			t.Fatal("Expected the handler to return after the request is canceled")
+		}
+	})
+
+	t.Run("CutShort", func(t *testing.T) {
+		// A stream of timestamps that is already written can only end early
+		ph := &PeriodHandler{S: NewService(logger.Sugar(), nil, Limits{}), L: logger.Sugar()}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest("GET",
+			"/ptlist?period=1m&tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+
+		start := time.Now()
+		ph.Router().ServeHTTP(rr, req)
+		assert.Less(t, time.Since(start), 5*time.Second, "Expected the stream to stop early")
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.True(t, strings.HasPrefix(rr.Body.String(), `["00010101T000000Z","00010101T000100Z"`))
+		assert.False(t, strings.HasSuffix(rr.Body.String(), "]\n"), "Expected the array to be cut short")
+	})
+}
+
+func TestPeriodHandler_Periods(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockService := new(mockPeriodService)
+	ph := &PeriodHandler{
+		S: mockService,
+		L: logger.Sugar(),
+	}
+
+	mockService.On("GetPeriods").Return([]period.Definition{
+		{Name: "1d", Description: "Every day"},
+		{Name: "1h", Description: "Every hour"},
+	})
+
+	req := httptest.NewRequest("GET", "/periods", nil)
+	rr := httptest.NewRecorder()
+	ph.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+
+	var list []periodResponse
+	err := json.NewDecoder(rr.Body).Decode(&list)
+	assert.NoError(t, err, "Expected no error while decoding JSON")
+	assert.Equal(t, []periodResponse{
+		{Name: "1d", Description: "Every day"},
+		{Name: "1h", Description: "Every hour"},
+	}, list)
+}
@@ -0,0 +1,164 @@
+package periodictask
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxLimit is the maximum number of timestamps of a page of the ptlist
+// endpoint
+const maxLimit = 10000
+
+// page is the optional page of the matching timestamps: at most limit of
+// them, after the timestamp of a cursor unless it is the first one.
+type page struct {
+	limit int
+	after time.Time
+	// key is the fingerprint of the schedule of the request
+	key string
+}
+
+// cursor is the content of an opaque cursor, the last timestamp of a page and
+// the fingerprint of the schedule that it belongs to.
+type cursor struct {
+	After time.Time `json:"after"`
+	Key   string    `json:"key"`
+}
+
+// parsePage gets the optional limit and cursor of the range t1 to t2 from url.
+// It writes the error response and reports false when one is invalid, or
+// when the cursor is of another schedule.
+func (h *PeriodHandler) parsePage(w http.ResponseWriter, r *http.Request, q query,
+	t1, t2 time.Time) (page, bool) {
+	pg := page{key: scheduleKey(q, t1, t2)}
+
+	// Get the optional number of timestamps of the page from url
+	if sl := r.URL.Query().Get("limit"); sl != "" {
+		var err error
+		if pg.limit, err = strconv.Atoi(sl); err != nil || pg.limit < 1 || pg.limit > maxLimit {
+			h.L.Error(sl + " is invalid limit")
+			httpError(w, http.StatusBadRequest, errInvalidLimit(sl))
+			return pg, false
+		}
+	}
+
+	// Get the optional cursor of the previous page from url
+	sc := r.URL.Query().Get("cursor")
+	if sc == "" {
+		return pg, true
+	}
+	c, err := decodeCursor(sc)
+	if err != nil || c.Key != pg.key {
+		h.L.Error(sc + " is invalid cursor")
+		httpError(w, http.StatusBadRequest, errInvalidCursor)
+		return pg, false
+	}
+	pg.after = c.After.UTC()
+	if pg.limit == 0 {
+		// The pages after the cursor are as large as they may be
+		pg.limit = maxLimit
+	}
+	if !q.opts.Bounds.Contains(pg.after, t1, t2) {
+		h.L.Error(sc + " is out of the range")
+		httpError(w, http.StatusBadRequest, errInvalidCursor)
+		return pg, false
+	}
+	return pg, true
+}
+
+// scheduleKey returns the fingerprint of the schedule of a request: the
+// period, the timezone, the range and the options, but not the formats of
+// the response, which may change from a page to the next.
+func scheduleKey(q query, t1, t2 time.Time) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%+v", q.period, q.tz,
+		t1.UTC().Format(time.RFC3339Nano), t2.UTC().Format(time.RFC3339Nano), q.opts)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// encodeCursor returns the opaque cursor of the page that ends at the time
+// after, in the URL safe base64 encoding of its JSON.
+func encodeCursor(after time.Time, key string) string {
+	b, _ := json.Marshal(cursor{After: after.UTC(), Key: key})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor returns the content of an opaque cursor.
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// writePage writes a page of the timestamps, with the link to the next page
+// in the Link header when there are more timestamps after it. The page is
+// generated before it is written, to tell whether there are.
+func (h *PeriodHandler) writePage(w http.ResponseWriter, r *http.Request, q query,
+	t1, t2 time.Time, pg page) {
+	list, more, err := h.S.GetPTPage(r.Context(), q.period, t1, t2, pg.after, pg.limit,
+		q.tz, q.opts)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	if more {
+		w.Header().Set("Link", "<"+nextLink(r, encodeCursor(list[len(list)-1], pg.key))+
+			`>; rel="next"`)
+	}
+	h.writeTimestamps(w, &listIterator{list: list}, q.out)
+}
+
+// nextLink returns the URL of the request with the cursor of the next page.
+func nextLink(r *http.Request, cursor string) string {
+	u := *r.URL
+	query := u.Query()
+	query.Set("cursor", cursor)
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// listIterator iterates over the timestamps of a page, which are already
+// generated.
+type listIterator struct {
+	list []time.Time
+	i    int
+}
+
+func (it *listIterator) Next() bool {
+	if it.i >= len(it.list) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+func (it *listIterator) Time() time.Time {
+	return it.list[it.i-1]
+}
+
+func (it *listIterator) Err() error {
+	return nil
+}
+
+// errInvalidCursor is used when the cursor is not the one of a next link of
+// the same request
+var errInvalidCursor = "invalid cursor. It should be the cursor of the next link of " +
+	"the same request"
+
+// errInvalidLimit is used when the number of timestamps of a page is invalid
+func errInvalidLimit(n string) string {
+	return n + " is not a valid limit. It should be from 1 to " + strconv.Itoa(maxLimit)
+}
+
+// errPageCalendar is used when a page of an iCalendar export is requested
+var errPageCalendar = "limit and cursor are not supported with ics"
@@ -0,0 +1,220 @@
+package periodictask
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestPeriodHandler_Page(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ph := &PeriodHandler{
+		S: NewService(logger.Sugar(), nil, Limits{}),
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func(rr *httptest.ResponseRecorder) []string {
+		var list []string
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		return list
+	}
+
+	t.Run("FollowNextLinks", func(t *testing.T) {
+		// Every 90 minutes aligned to the local midnight, from a t1 that is
+		// not aligned, with both ends
+		query := "/ptlist?period=90m&tz=Europe/Athens&bounds=[]" +
+			"&t1=20210729T001000Z&t2=20210731T003000Z"
+		all := list(get(query))
+
+		var pages [][]string
+		for path := query + "&limit=10"; path != ""; {
+			rr := get(path)
+			assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+			pages = append(pages, list(rr))
+
+			path = ""
+			if link := rr.Header().Get("Link"); link != "" {
+				assert.True(t, strings.HasSuffix(link, `>; rel="next"`))
+				path = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+			}
+		}
+
+		assert.Len(t, pages, 4)
+		var got []string
+		for _, p := range pages {
+			got = append(got, p...)
+		}
+		assert.Equal(t, all, got)
+		assert.Len(t, pages[3], len(all)-30)
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		rr := get("/ptlist?period=1h&tz=UTC&t1=20210729T000000Z&t2=20210729T030000Z&limit=3")
+
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Len(t, list(rr), 3)
+		assert.Empty(t, rr.Header().Get("Link"))
+	})
+
+	t.Run("OtherFormat", func(t *testing.T) {
+		// The cursor does not depend on the formats of the response
+		query := "/ptlist?period=1h&tz=UTC&t1=20210729T000000Z&t2=20210729T030000Z"
+		cursor := nextCursor(t, get(query+"&limit=2"))
+
+		rr := get(query + "&format=csv&out=epoch&cursor=" + cursor)
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Equal(t, "timestamp\n1627524000\n", rr.Body.String())
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		for _, limit := range []string{"0", "-1", "10001", "ten"} {
+			rr := get("/ptlist?period=1h&tz=UTC&t1=20210729T000000Z&t2=20210729T030000Z" +
+				"&limit=" + limit)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status BadRequest")
+			assert.Equal(t, `{"status":"error","desc":"`+errInvalidLimit(limit)+`"}`+"\n",
+				rr.Body.String())
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		query := "/ptlist?period=1h&tz=UTC&t1=20210729T000000Z&t2=20210729T030000Z"
+		other := encodeCursor(time.Date(2021, 7, 29, 1, 0, 0, 0, time.UTC), "0")
+
+		// The cursor of another schedule, or of another range
+		cursor := nextCursor(t, get(query+"&limit=1"))
+		for _, path := range []string{
+			query + "&cursor=garbage",
+			query + "&cursor=" + other,
+			strings.Replace(query, "period=1h", "period=2h", 1) + "&cursor=" + cursor,
+			strings.Replace(query, "T000000Z", "T020000Z", 1) + "&cursor=" + cursor,
+		} {
+			rr := get(path)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status BadRequest")
+			assert.Equal(t, `{"status":"error","desc":"`+errInvalidCursor+`"}`+"\n",
+				rr.Body.String())
+		}
+	})
+
+	t.Run("Calendar", func(t *testing.T) {
+		rr := get("/ptlist?period=1h&tz=UTC&t1=20210729T000000Z&t2=20210729T030000Z" +
+			"&limit=1&format=ics")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status BadRequest")
+		assert.Equal(t, `{"status":"error","desc":"`+errPageCalendar+`"}`+"\n", rr.Body.String())
+	})
+}
+
+func TestPeriodHandler_Limits(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ph := &PeriodHandler{
+		S: NewService(logger.Sugar(), nil, Limits{
+			MaxCount: 100,
+			MaxRange: map[string]time.Duration{"": 366 * 24 * time.Hour},
+		}),
+		L: logger.Sugar(),
+	}
+	r := ph.Router()
+
+	get := func(path string) (*httptest.ResponseRecorder, responseError) {
+		req := httptest.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var errorMsg responseError
+		if rr.Code != http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errorMsg))
+		}
+		return rr, errorMsg
+	}
+
+	t.Run("RangeTooLong", func(t *testing.T) {
+		rr, errorMsg := get("/ptlist?period=1h&tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status BadRequest")
+		assert.Equal(t, &limitResponse{MaxRange: "8784h0m0s"}, errorMsg.Limit)
+		assert.Equal(t, hintPagination, errorMsg.Hint)
+		assert.Contains(t, errorMsg.Desc, "range too long")
+
+		// A count may generate the timestamps too, and its message has the
+		// kind of the period rather than the period
+		rr, errorMsg = get("/ptcount?period=0+9+*+*+*&tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected status BadRequest")
+		assert.Equal(t, &limitResponse{MaxRange: "8784h0m0s"}, errorMsg.Limit)
+		assert.Contains(t, errorMsg.Desc, "the maximum range of a cron period is")
+	})
+
+	t.Run("TooManyTimestamps", func(t *testing.T) {
+		for _, format := range []string{"json", "ics"} {
+			rr, errorMsg := get("/ptlist?period=1h&tz=UTC&format=" + format +
+				"&t1=20210101T000000Z&t2=20210201T000000Z")
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code, "Expected status 413")
+			assert.Equal(t, &limitResponse{MaxCount: 100}, errorMsg.Limit)
+			assert.Equal(t, hintPagination, errorMsg.Hint)
+		}
+	})
+
+	t.Run("FarAnchor", func(t *testing.T) {
+		// An anchor more than 292 years before the range is counted
+		// arithmetically, rather than by the steps from it
+		for period, expected := range map[string]int{"1h": 1, "1m": 60, "PT1M": 60} {
+			query := "?period=" + period + "&tz=UTC&anchor=20200101T000000Z" +
+				"&t1=25000101T000000Z&t2=25000101T010000Z"
+
+			rr, _ := get("/ptlist" + query)
+			assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+			var list []string
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			assert.Len(t, list, expected, period)
+
+			rr, _ = get("/ptcount" + query)
+			assert.Equal(t, fmt.Sprintf(`{"count":%d}`+"\n", expected), rr.Body.String(), period)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		// The pages of a longer range than the maximum
+		rr, _ := get("/ptlist?period=1h&tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z&limit=100")
+		assert.Equal(t, http.StatusOK, rr.Code, "Expected status OK")
+		assert.Contains(t, rr.Header().Get("Link"), `rel="next"`)
+
+		// A cursor without limit continues with the largest pages, which
+		// the maximum count still bounds
+		cursor := nextCursor(t, rr)
+		rr, errorMsg := get("/ptlist?period=1h&tz=UTC&t1=00010101T000000Z" +
+			"&t2=99991231T235959Z&cursor=" + cursor)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code, "Expected status 413")
+		assert.Equal(t, &limitResponse{MaxCount: 100}, errorMsg.Limit)
+
+		rr, _ = get("/ptlist?period=1h&tz=UTC&t1=00010101T000000Z&t2=99991231T235959Z" +
+			"&limit=100&cursor=" + cursor)
+		var list []string
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		assert.Equal(t, "00010105T040000Z", list[0])
+	})
+}
+
+// nextCursor returns the cursor of the next link of a response.
+func nextCursor(t *testing.T, rr *httptest.ResponseRecorder) string {
+	link := strings.TrimSuffix(strings.TrimPrefix(rr.Header().Get("Link"), "<"), `>; rel="next"`)
+	u, err := url.Parse(link)
+	assert.NoError(t, err)
+	return u.Query().Get("cursor")
+}
@@ -3,42 +3,344 @@ package periodictask
 import (
 	"context"
 	"errors"
+	"fmt"
+	"periodic-task/pkg/holiday"
 	"periodic-task/pkg/period"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-// errUnsupportedPeriod is used when the requested period is not supported
-var errUnsupportedPeriod = errors.New("unsupported period")
+var (
+	// errUnsupportedPeriod is used when the requested period is not supported
+	errUnsupportedPeriod = errors.New("unsupported period")
+	// errUnknownCalendar is used when the requested holiday calendar is not loaded
+	errUnknownCalendar = errors.New("unknown calendar")
+	// errTooManyTimestamps is used when a list exceeds the maximum count
+	errTooManyTimestamps = errors.New("too many timestamps")
+	// errRangeTooLong is used when the range of a list exceeds the maximum range
+	errRangeTooLong = errors.New("range too long")
+)
+
+// Limits bound the lists of timestamps of the service, so that a request
+// cannot tie it up. A zero limit is no limit.
+type Limits struct {
+	// MaxCount is the maximum number of timestamps of a list.
+	MaxCount int
+	// MaxRange is the maximum range from t1 to t2 of a list or a count by
+	// kind of period (see period.Kind, e.g. 1h or cron), and of the other
+	// kinds by the empty kind.
+	MaxRange map[string]time.Duration
+}
+
+// maxRange returns the maximum range of the lists and the counts of the kind
+// of period.
+func (l Limits) maxRange(kind string) time.Duration {
+	if max, ok := l.MaxRange[kind]; ok {
+		return max
+	}
+	return l.MaxRange[""]
+}
+
+// limitError is used when a list exceeds a limit of the service, which is
+// either errTooManyTimestamps or errRangeTooLong.
+type limitError struct {
+	err      error
+	kind     string
+	maxCount int
+	maxRange time.Duration
+}
+
+func (e *limitError) Error() string {
+	if errors.Is(e.err, errRangeTooLong) {
+		return fmt.Sprintf("%v: the maximum range of a %s is %s", e.err, kindName(e.kind), e.maxRange)
+	}
+	return fmt.Sprintf("%v: the maximum is %d", e.err, e.maxCount)
+}
+
+func (e *limitError) Unwrap() error {
+	return e.err
+}
 
 // Service is the interface that provides period-task methods
 type Service interface {
+	// GetPTList returns the matching timestamps formatted in UTC and in the
+	// period.SUPPORTEDFORMAT, the form of GetPTTimes for existing callers.
 	GetPTList(
 		ctx context.Context, period string, t1, t2 time.Time, tz *time.Location,
+		opts period.Options,
 	) ([]string, error)
+	// GetPTTimes returns the matching timestamps in the requested timezone.
+	GetPTTimes(
+		ctx context.Context, period string, t1, t2 time.Time, tz *time.Location,
+		opts period.Options,
+	) ([]time.Time, error)
+	// IterPTList returns an iterator of the matching timestamps, which
+	// generates them while they are consumed. It fails with a limitError
+	// when they exceed the limits of the service.
+	IterPTList(
+		ctx context.Context, period string, t1, t2 time.Time, tz *time.Location,
+		opts period.Options,
+	) (period.Iterator, error)
+	// GetPTPage returns a page of at most limit matching timestamps after the
+	// time after, the last one of the previous page, or from t1 when it is
+	// zero, and whether there are more after them. Unlike the whole lists,
+	// the pages are not bound by the maximum range.
+	GetPTPage(
+		ctx context.Context, period string, t1, t2, after time.Time, limit int,
+		tz *time.Location, opts period.Options,
+	) ([]time.Time, bool, error)
+	// GetNext returns the next n matching timestamps after the time after,
+	// or fewer if the period ends.
+	GetNext(
+		ctx context.Context, period string, after time.Time, n int, tz *time.Location,
+		opts period.Options,
+	) ([]time.Time, error)
+	// GetPrev returns the previous matching timestamp before the time before,
+	// or none if there is no such timestamp.
+	GetPrev(
+		ctx context.Context, period string, before time.Time, tz *time.Location,
+		opts period.Options,
+	) ([]time.Time, error)
+	// GetPTCount returns the number of matching timestamps, without
+	// generating them where the period allows it.
+	GetPTCount(
+		ctx context.Context, period string, t1, t2 time.Time, tz *time.Location,
+		opts period.Options,
+	) (int, error)
+	// GetPTMatch reports whether t is a matching timestamp.
+	GetPTMatch(
+		ctx context.Context, period string, t time.Time, tz *time.Location,
+		opts period.Options,
+	) (bool, error)
+	// GetPTRule returns the RFC 5545 recurrence rule of the matching
+	// timestamps on the wall clock of the requested timezone, or false when
+	// they do not map cleanly to one (see period.RecurrenceRule).
+	GetPTRule(
+		ctx context.Context, period string, tz *time.Location, opts period.Options,
+	) (string, bool, error)
+	// GetPeriods returns the supported periods, the registered ones and the
+	// built-in forms.
+	GetPeriods() []period.Definition
 }
 
 func (s *service) GetPTList(
 	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
 ) ([]string, error) {
-	// Get a period object
-	period := period.NewPeriod(p)
-	if period == nil {
-		s.l.Error(p, " is unsupported period")
-		return nil, errUnsupportedPeriod
+	list, err := s.GetPTTimes(ctx, p, t1, t2, tz, opts)
+	if err != nil {
+		return nil, err
+	}
+	return period.Format(list), nil
+}
+
+func (s *service) GetPTTimes(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	it, err := s.IterPTList(ctx, p, t1, t2, tz, opts)
+	if err != nil {
+		return nil, err
 	}
+
 	// Return the matching timestamps
-	return period.GetMatchingTimestamps(t1, t2, tz), nil
+	return period.Collect(it)
+}
+
+func (s *service) IterPTList(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) (period.Iterator, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the range before the number of timestamps, which may have to
+	// be generated
+	if err := s.checkRange(strategy, t1, t2); err != nil {
+		return nil, err
+	}
+	if s.limits.MaxCount > 0 {
+		exceeds, err := opts.Bounds.Exceeds(ctx, strategy, t1, t2, tz, s.limits.MaxCount)
+		if err != nil {
+			return nil, err
+		}
+		if exceeds {
+			s.l.Error("the ", kindName(period.Kind(strategy)), " has more than ",
+				s.limits.MaxCount, " timestamps")
+			return nil, &limitError{err: errTooManyTimestamps, maxCount: s.limits.MaxCount}
+		}
+	}
+
+	return opts.Bounds.Iter(ctx, strategy, t1, t2, tz), nil
+}
+
+func (s *service) GetPTPage(
+	ctx context.Context, p string, t1, t2, after time.Time, limit int,
+	tz *time.Location, opts period.Options,
+) ([]time.Time, bool, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.limits.MaxCount > 0 && limit > s.limits.MaxCount {
+		s.l.Error("the page of ", limit, " timestamps is larger than ", s.limits.MaxCount)
+		return nil, false, &limitError{err: errTooManyTimestamps, maxCount: s.limits.MaxCount}
+	}
+
+	it := opts.Bounds.Iter(ctx, strategy, t1, t2, tz)
+	if !after.IsZero() {
+		it = opts.Bounds.Resume(ctx, strategy, t1, t2, after, tz)
+	}
+
+	// Generate one more timestamp, which tells whether there are more
+	list := make([]time.Time, 0, limit)
+	more := it.Next()
+	for ; more && len(list) < limit; more = it.Next() {
+		list = append(list, it.Time())
+	}
+	if err := it.Err(); err != nil {
+		return nil, false, err
+	}
+	return list, more, nil
+}
+
+func (s *service) GetNext(
+	ctx context.Context, p string, after time.Time, n int, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := period.NextN(ctx, strategy, after, n, tz)
+	if err != nil && !errors.Is(err, period.ErrNoMatch) {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *service) GetPrev(
+	ctx context.Context, p string, before time.Time, tz *time.Location,
+	opts period.Options,
+) ([]time.Time, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, err := period.Prev(ctx, strategy, before, tz)
+	switch {
+	case errors.Is(err, period.ErrNoMatch):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return []time.Time{prev}, nil
+	}
+}
+
+func (s *service) GetPTCount(
+	ctx context.Context, p string, t1, t2 time.Time, tz *time.Location,
+	opts period.Options,
+) (int, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	// A count may have to generate the timestamps
+	if err := s.checkRange(strategy, t1, t2); err != nil {
+		return 0, err
+	}
+	return opts.Bounds.Count(ctx, strategy, t1, t2, tz)
+}
+
+func (s *service) GetPTMatch(
+	ctx context.Context, p string, t time.Time, tz *time.Location,
+	opts period.Options,
+) (bool, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return false, err
+	}
+	return period.Matches(ctx, strategy, t, tz)
+}
+
+func (s *service) GetPTRule(
+	ctx context.Context, p string, tz *time.Location, opts period.Options,
+) (string, bool, error) {
+	strategy, err := s.strategy(p, opts)
+	if err != nil {
+		return "", false, err
+	}
+	rule, ok := period.RecurrenceRule(strategy, tz)
+	return rule, ok, nil
+}
+
+func (s *service) GetPeriods() []period.Definition {
+	return period.Supported()
+}
+
+// strategy returns the period object of the requested period and options.
+func (s *service) strategy(p string, opts period.Options) (period.Period, error) {
+	// Get a period object
+	strategy, err := period.ParsePeriodWithOptions(p, opts)
+	if err != nil {
+		s.l.Error(p, " is unsupported period: ", err)
+		return nil, fmt.Errorf("%w: %v", errUnsupportedPeriod, err)
+	}
+
+	// Move the timestamps on non-working days
+	if opts.Calendar != "" || opts.Adjust != period.NoAdjustment {
+		ap := period.AdjustedPeriod{Period: strategy, Convention: opts.Adjust, DST: opts.DST}
+		if opts.Calendar != "" {
+			cal, ok := s.calendars[opts.Calendar]
+			if !ok {
+				s.l.Error(opts.Calendar, " is unknown calendar")
+				return nil, fmt.Errorf("%w: %s", errUnknownCalendar, opts.Calendar)
+			}
+			ap.Calendar = cal
+		}
+		strategy = ap
+	}
+
+	return strategy, nil
+}
+
+// checkRange checks that the range from t1 to t2 is not longer than the
+// maximum range of the kind of the period p.
+func (s *service) checkRange(p period.Period, t1, t2 time.Time) error {
+	kind := period.Kind(p)
+	if max := s.limits.maxRange(kind); max > 0 && t2.Sub(t1) > max {
+		s.l.Error("the range of a ", kindName(kind), " is longer than ", max)
+		return &limitError{err: errRangeTooLong, kind: kind, maxRange: max}
+	}
+	return nil
+}
+
+// kindName returns the name of the kind of a period in the messages.
+func kindName(kind string) string {
+	if kind == "" {
+		return "period"
+	}
+	return kind + " period"
 }
 
 type service struct {
-	l *zap.SugaredLogger
+	l         *zap.SugaredLogger
+	calendars holiday.Store
+	limits    Limits
 }
 
 // NewService creates a period service with necessary dependencies
-func NewService(logger *zap.SugaredLogger) Service {
+func NewService(logger *zap.SugaredLogger, calendars holiday.Store, limits Limits) Service {
 	return &service{
-		l: logger,
+		l:         logger,
+		calendars: calendars,
+		limits:    limits,
 	}
 }
@@ -2,6 +2,10 @@ package periodictask
 
 import (
 	"context"
+	"errors"
+	"periodic-task/pkg/holiday"
+	"periodic-task/pkg/period"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,14 +14,14 @@ import (
 
 func TestService_GetPTList(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
-	service := NewService(logger.Sugar())
+	service := NewService(logger.Sugar(), nil, Limits{})
 
 	tz, _ := time.LoadLocation("Europe/Athens")
 	t1, _ := time.Parse("20060102T150405Z", "20210729T000000Z")
 	t2, _ := time.Parse("20060102T150405Z", "20210729T050000Z")
 
 	t.Run("ValidRequest", func(t *testing.T) {
-		period := "1h"
+		p := "1h"
 		expected := []string{
 			"20210729T000000Z",
 			"20210729T010000Z",
@@ -26,7 +30,7 @@ func TestService_GetPTList(t *testing.T) {
 			"20210729T040000Z",
 		}
 
-		result, err := service.GetPTList(context.Background(), period, t1, t2, tz)
+		result, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
 		if err != nil {
 			t.Fatalf("Expected no error, but got: %v", err)
 		}
@@ -42,13 +46,498 @@ func TestService_GetPTList(t *testing.T) {
 		}
 	})
 
+	t.Run("MultiplePeriod", func(t *testing.T) {
+		// Aligned to the local (UTC+3) midnight
+		p := "2h"
+		expected := []string{
+			"20210729T010000Z",
+			"20210729T030000Z",
+		}
+
+		result, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("InvalidMultiplePeriod", func(t *testing.T) {
+		for _, p := range []string{"0h", "15", "h", "15s", "-1d"} {
+			_, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
+			if !errors.Is(err, errUnsupportedPeriod) {
+				t.Errorf("Expected unsupported period error for %s, but got: %v", p, err)
+			}
+		}
+	})
+
+	t.Run("InvocationPoint", func(t *testing.T) {
+		at, _ := period.ParseInvocation("minute 30")
+		expected := []string{
+			"20210729T003000Z",
+			"20210729T013000Z",
+			"20210729T023000Z",
+			"20210729T033000Z",
+			"20210729T043000Z",
+		}
+
+		result, err := service.GetPTList(context.Background(), "1h", t1, t2, tz,
+			period.Options{At: at})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("InvalidInvocationPoint", func(t *testing.T) {
+		at, _ := period.ParseInvocation("day 15")
+		_, err := service.GetPTList(context.Background(), "1h", t1, t2, tz,
+			period.Options{At: at})
+		if !errors.Is(err, errUnsupportedPeriod) {
+			t.Errorf("Expected unsupported period error, but got: %v", err)
+		}
+	})
+
+	t.Run("BusinessDayAdjustment", func(t *testing.T) {
+		gr := holiday.NewCalendar("GR")
+		gr.Add(time.Date(2021, time.May, 3, 0, 0, 0, 0, time.UTC), "Easter Monday")
+		service := NewService(logger.Sugar(), holiday.Store{"GR": gr}, Limits{})
+
+		// May 1 is a Saturday and May 3 a holiday
+		t1, _ := time.Parse("20060102T150405Z", "20210401T000000Z")
+		t2, _ := time.Parse("20060102T150405Z", "20210701T000000Z")
+		at, _ := period.ParseInvocation("day 1 at 09:00")
+		expected := []string{
+			"20210401T060000Z",
+			"20210504T060000Z",
+			"20210601T060000Z",
+		}
+
+		result, err := service.GetPTList(context.Background(), "1mo", t1, t2, tz,
+			period.Options{At: at, Calendar: "GR", Adjust: period.Following})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("UnknownCalendar", func(t *testing.T) {
+		_, err := service.GetPTList(context.Background(), "1mo", t1, t2, tz,
+			period.Options{Calendar: "XX", Adjust: period.Following})
+		if !errors.Is(err, errUnknownCalendar) {
+			t.Errorf("Expected unknown calendar error, but got: %v", err)
+		}
+	})
+
+	t.Run("Times", func(t *testing.T) {
+		result, err := service.GetPTTimes(context.Background(), "1d", t1, t2.AddDate(0, 0, 2),
+			tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != 2 {
+			t.Fatalf("Expected 2 timestamps, but got %d", len(result))
+		}
+
+		// The local midnights in the requested timezone
+		for i, ts := range result {
+			if ts.Location() != tz || ts.Hour() != 0 || ts.Day() != 30+i {
+				t.Errorf("Expected the local midnight of day %d, but got %s", 30+i, ts)
+			}
+			if _, offset := ts.Zone(); offset != 3*60*60 {
+				t.Errorf("Expected the +03:00 offset, but got %d", offset)
+			}
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		it, err := service.IterPTList(context.Background(), "1h", t1, t2, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		n := 0
+		for it.Next() {
+			if expected := t1.Add(time.Duration(n) * time.Hour); !it.Time().Equal(expected) {
+				t.Errorf("Expected %s, but got %s", expected, it.Time())
+			}
+			n++
+		}
+		if n != 5 || it.Err() != nil {
+			t.Errorf("Expected 5 timestamps without error, but got %d and %v", n, it.Err())
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := service.GetPTList(ctx, "1h", t1, t2, tz, period.Options{})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected canceled error, but got: %v", err)
+		}
+	})
+
+	t.Run("Next", func(t *testing.T) {
+		result, err := service.GetNext(context.Background(), "1h", t1.Add(time.Minute), 2, tz,
+			period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		expected := []time.Time{t1.Add(time.Hour), t1.Add(2 * time.Hour)}
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+		for i := range result {
+			if !result[i].Equal(expected[i]) {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("NextEnded", func(t *testing.T) {
+		// The rule fires only twice
+		p := "DTSTART:20210729T090000Z\nRRULE:FREQ=DAILY;COUNT=2"
+		result, err := service.GetNext(context.Background(), p, t1, 5, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(result) != 2 {
+			t.Errorf("Expected 2 timestamps, but got %d", len(result))
+		}
+	})
+
+	t.Run("Prev", func(t *testing.T) {
+		result, err := service.GetPrev(context.Background(), "1h", t1.Add(time.Minute), tz,
+			period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(result) != 1 || !result[0].Equal(t1) {
+			t.Errorf("Expected %s, but got %v", t1, result)
+		}
+	})
+
+	t.Run("NoPrev", func(t *testing.T) {
+		p := "DTSTART:20210729T090000Z\nRRULE:FREQ=DAILY;COUNT=2"
+		result, err := service.GetPrev(context.Background(), p, t1, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(result) != 0 {
+			t.Errorf("Expected no timestamp, but got %v", result)
+		}
+	})
+
+	t.Run("NoPhase", func(t *testing.T) {
+		// An ISO 8601 duration would start at the requested time itself
+		if _, err := service.GetNext(context.Background(), "PT1H", t1, 1, tz,
+			period.Options{}); !errors.Is(err, period.ErrNoPhase) {
+			t.Errorf("Expected ErrNoPhase, but got: %v", err)
+		}
+		if _, err := service.GetPrev(context.Background(), "PT1H", t1, tz,
+			period.Options{}); !errors.Is(err, period.ErrNoPhase) {
+			t.Errorf("Expected ErrNoPhase, but got: %v", err)
+		}
+
+		anchor, _ := period.ParseAnchor("2021-07-01T01:30")
+		result, err := service.GetNext(context.Background(), "PT1H", t1, 1, tz,
+			period.Options{Anchor: anchor})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(result) != 1 || result[0].Minute() != 30 {
+			t.Errorf("Expected the next half hour, but got %v", result)
+		}
+	})
+
+	t.Run("Anchor", func(t *testing.T) {
+		// Every 2 hours from 01:30 local (UTC+3), whatever t1 is
+		anchor, _ := period.ParseAnchor("2021-07-01T01:30")
+		expected := []string{
+			"20210729T003000Z",
+			"20210729T023000Z",
+			"20210729T043000Z",
+		}
+
+		result, err := service.GetPTList(context.Background(), "2h", t1, t2, tz,
+			period.Options{Anchor: anchor})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		n, err := service.GetPTCount(context.Background(), "1h", t1, t2, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if n != 5 {
+			t.Errorf("Expected 5 timestamps, but got %d", n)
+		}
+	})
+
+	t.Run("Bounds", func(t *testing.T) {
+		// t1 and t2 are both on the hour
+		for bounds, expected := range map[period.Bounds]int{
+			period.ClosedOpen: 5,
+			period.Closed:     6,
+			period.OpenClosed: 5,
+		} {
+			opts := period.Options{Bounds: bounds}
+			result, err := service.GetPTList(context.Background(), "1h", t1, t2, tz, opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(result) != expected {
+				t.Errorf("Expected %d timestamps in %s, but got %d", expected, bounds, len(result))
+			}
+			if bounds == period.OpenClosed && result[0] == "20210729T000000Z" {
+				t.Errorf("Expected t1 to be excluded from %s", bounds)
+			}
+
+			n, err := service.GetPTCount(context.Background(), "1h", t1, t2, tz, opts)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if n != expected {
+				t.Errorf("Expected a count of %d in %s, but got %d", expected, bounds, n)
+			}
+		}
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		// The first page, and the one after its last timestamp, aligned to
+		// the local midnight
+		list, more, err := service.GetPTPage(context.Background(), "1h", t1, t2, time.Time{}, 2,
+			tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if result := period.Format(list); len(result) != 2 || !more {
+			t.Errorf("Expected 2 timestamps and more, but got %v and %v", result, more)
+		}
+
+		list, more, err = service.GetPTPage(context.Background(), "2h", t1, t2, t1.Add(time.Hour),
+			2, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		result := period.Format(list)
+		if len(result) != 1 || result[0] != "20210729T030000Z" || more {
+			t.Errorf("Expected [20210729T030000Z] and no more, but got %v and %v", result, more)
+		}
+
+		_, _, err = service.GetPTPage(context.Background(), "invalid", t1, t2, t1, 2, tz,
+			period.Options{})
+		if !errors.Is(err, errUnsupportedPeriod) {
+			t.Errorf("Expected unsupported period error, but got: %v", err)
+		}
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		service := NewService(logger.Sugar(), nil, Limits{
+			MaxCount: 10,
+			MaxRange: map[string]time.Duration{"": 48 * time.Hour, "1d": 30 * 24 * time.Hour},
+		})
+
+		// Within the limits
+		for _, p := range []string{"1h", "1d", "0 * * * *"} {
+			if _, err := service.GetPTList(context.Background(), p, t1, t2, tz,
+				period.Options{}); err != nil {
+				t.Errorf("Expected no error for %s, but got: %v", p, err)
+			}
+		}
+
+		// More timestamps, counted or generated, than the maximum count
+		for _, p := range []string{"15m", "*/5 * * * *"} {
+			_, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
+			var le *limitError
+			if !errors.As(err, &le) || !errors.Is(err, errTooManyTimestamps) || le.maxCount != 10 {
+				t.Errorf("Expected too many timestamps error for %s, but got: %v", p, err)
+			}
+		}
+
+		// A longer range than the maximum of the period
+		_, err := service.GetPTList(context.Background(), "1h", t1, t1.AddDate(0, 0, 3), tz,
+			period.Options{})
+		var le *limitError
+		if !errors.As(err, &le) || !errors.Is(err, errRangeTooLong) || le.maxRange != 48*time.Hour {
+			t.Errorf("Expected range too long error, but got: %v", err)
+		}
+		if _, err := service.GetPTList(context.Background(), "1d", t1, t1.AddDate(0, 0, 3), tz,
+			period.Options{}); err != nil {
+			t.Errorf("Expected no error for the range of 1d, but got: %v", err)
+		}
+
+		// The limits are by the kind of the period, whatever its text, and
+		// bound the counts too
+		byKind := NewService(logger.Sugar(), nil, Limits{
+			MaxRange: map[string]time.Duration{"": 48 * time.Hour, "cron": time.Hour},
+		})
+		schedule := `{"union":[{"period":"1d"},{"period":"0 12 * * *"}]}`
+		for p, max := range map[string]time.Duration{"0 9 * * *": time.Hour,
+			"@daily": time.Hour, schedule: 48 * time.Hour} {
+			_, err := byKind.GetPTList(context.Background(), p, t1, t1.AddDate(0, 0, 3), tz,
+				period.Options{})
+			if !errors.As(err, &le) || le.maxRange != max || strings.Contains(err.Error(), p) {
+				t.Errorf("Expected range too long error by kind for %s, but got: %v", p, err)
+			}
+			_, err = byKind.GetPTCount(context.Background(), p, t1, t1.AddDate(0, 0, 3), tz,
+				period.Options{})
+			if !errors.As(err, &le) || le.maxRange != max {
+				t.Errorf("Expected range too long error of a count for %s, but got: %v", p, err)
+			}
+		}
+		if _, err := byKind.GetPTCount(context.Background(), "1d", t1, t1.AddDate(0, 0, 2), tz,
+			period.Options{}); err != nil {
+			t.Errorf("Expected no error for a count within the range, but got: %v", err)
+		}
+
+		// The pages are not bound by the range, but by the count
+		if _, _, err := service.GetPTPage(context.Background(), "1h", t1, t1.AddDate(1, 0, 0),
+			time.Time{}, 10, tz, period.Options{}); err != nil {
+			t.Errorf("Expected no error for a page, but got: %v", err)
+		}
+		_, _, err = service.GetPTPage(context.Background(), "1h", t1, t2, time.Time{}, 11, tz,
+			period.Options{})
+		if !errors.Is(err, errTooManyTimestamps) {
+			t.Errorf("Expected too many timestamps error for a page, but got: %v", err)
+		}
+	})
+
+	t.Run("Rule", func(t *testing.T) {
+		rule, ok, err := service.GetPTRule(context.Background(), "1q", tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if !ok || rule != "FREQ=MONTHLY;INTERVAL=3" {
+			t.Errorf("Expected the quarterly rule, but got %q and %v", rule, ok)
+		}
+
+		// The adjusted timestamps do not follow a rule
+		_, ok, err = service.GetPTRule(context.Background(), "1q", tz,
+			period.Options{Adjust: period.Following})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if ok {
+			t.Errorf("Expected no rule for the adjusted timestamps")
+		}
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		for ts, expected := range map[time.Time]bool{t1: true, t1.Add(time.Minute): false} {
+			match, err := service.GetPTMatch(context.Background(), "1h", ts, tz, period.Options{})
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if match != expected {
+				t.Errorf("Expected %s to match %t, but got %t", ts, expected, match)
+			}
+		}
+	})
+
+	t.Run("MatchNoPhase", func(t *testing.T) {
+		// An ISO 8601 duration would match any time
+		if _, err := service.GetPTMatch(context.Background(), "PT1H", t1, tz,
+			period.Options{}); !errors.Is(err, period.ErrNoPhase) {
+			t.Errorf("Expected ErrNoPhase, but got: %v", err)
+		}
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		// Every hour, except from 01:00 to 03:00 in local time (UTC+3)
+		p := `{"outside": {"from": "01:00", "to": "03:00"}, "schedule": {"period": "1h"}}`
+		t1, _ := time.Parse("20060102T150405Z", "20210728T210000Z")
+		expected := []string{
+			"20210728T210000Z",
+			"20210729T000000Z",
+			"20210729T010000Z",
+			"20210729T020000Z",
+			"20210729T030000Z",
+			"20210729T040000Z",
+		}
+
+		result, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d timestamps, but got %d", len(expected), len(result))
+		}
+
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("Expected %s, but got %s", expected[i], result[i])
+			}
+		}
+	})
+
+	t.Run("Periods", func(t *testing.T) {
+		defs := service.GetPeriods()
+		if len(defs) == 0 || defs[0].Name != period.ONEDAY {
+			t.Errorf("Expected the registered periods sorted by name, but got %v", defs)
+		}
+
+		// The built-in forms follow the registered periods
+		names := make(map[string]bool)
+		for _, d := range defs {
+			names[d.Name] = true
+		}
+		for _, name := range []string{"<N><unit>", "P<duration>", "<cron>", "<rrule>", "<schedule>"} {
+			if !names[name] {
+				t.Errorf("Expected the built-in form %s, but got %v", name, defs)
+			}
+		}
+	})
+
 	t.Run("UnsupportedPeriod", func(t *testing.T) {
-		period := "invalid"
-		_, err := service.GetPTList(context.Background(), period, t1, t2, tz)
+		p := "invalid"
+		_, err := service.GetPTList(context.Background(), p, t1, t2, tz, period.Options{})
 
 		if err == nil {
 			t.Error("Expected error for unsupported period, but got no error")
-		} else if err != errUnsupportedPeriod {
+		} else if !errors.Is(err, errUnsupportedPeriod) {
 			t.Errorf("Expected unsupported period error, but got: %v", err)
 		}
 	})
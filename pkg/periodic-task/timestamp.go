@@ -0,0 +1,200 @@
+package periodictask
+
+import (
+	"errors"
+	"fmt"
+	"periodic-task/pkg/period"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampFormat parses a timestamp of a request. The timestamps without an
+// offset are in the requested timezone tz.
+type TimestampFormat func(s string, tz *time.Location) (time.Time, error)
+
+// errInvalidTimestamp is used when a timestamp is not in a format
+var errInvalidTimestamp = errors.New("invalid timestamp")
+
+// autoFormat is the name of the auto-detected format, the default
+const autoFormat = "auto"
+
+// timestampFormats are the built-in formats by their tf name
+var timestampFormats = map[string]TimestampFormat{
+	"basic":   parseBasic,
+	"rfc3339": parseRFC3339,
+	"epoch":   parseEpoch,
+	"epochms": parseEpochMilli,
+	"local":   parseLocal,
+}
+
+// localLayouts are the forms of a wall clock in the requested timezone
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"20060102T150405",
+}
+
+// timestampFormat returns the format of the tf parameter. The handler's
+// formats take precedence over the built-in ones, and an empty name is the
+// auto-detected format.
+func (h *PeriodHandler) timestampFormat(name string) (TimestampFormat, bool) {
+	if name == "" || name == autoFormat {
+		return parseAuto, true
+	}
+	if f, ok := h.Formats[name]; ok {
+		return f, true
+	}
+	f, ok := timestampFormats[name]
+	return f, ok
+}
+
+// parseAuto detects the format of a timestamp: the period.SUPPORTEDFORMAT,
+// RFC 3339, a Unix epoch, in milliseconds with more than 11 digits and in
+// seconds otherwise, or a local time.
+func parseAuto(s string, tz *time.Location) (time.Time, error) {
+	if isEpoch(s) {
+		if len(strings.TrimPrefix(s, "-")) > 11 {
+			return parseEpochMilli(s, tz)
+		}
+		return parseEpoch(s, tz)
+	}
+	for _, f := range []TimestampFormat{parseBasic, parseRFC3339, parseLocal} {
+		if t, err := f(s, tz); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errInvalidTimestamp
+}
+
+// parseBasic parses a timestamp in UTC and in the period.SUPPORTEDFORMAT.
+func parseBasic(s string, _ *time.Location) (time.Time, error) {
+	return time.Parse(period.SUPPORTEDFORMAT, s)
+}
+
+// parseRFC3339 parses an RFC 3339 timestamp, with Z or an offset. The +
+// of an offset that was not escaped in the url is decoded as a space, which
+// is read back as a +.
+func parseRFC3339(s string, _ *time.Location) (time.Time, error) {
+	if i := strings.LastIndex(s, " "); i > len("2006-01-02") {
+		s = s[:i] + "+" + s[i+1:]
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t.UTC(), err
+}
+
+// parseEpoch parses the seconds since the Unix epoch.
+func parseEpoch(s string, _ *time.Location) (time.Time, error) {
+	if !isEpoch(s) {
+		return time.Time{}, errInvalidTimestamp
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return time.Unix(n, 0).UTC(), err
+}
+
+// parseEpochMilli parses the milliseconds since the Unix epoch.
+func parseEpochMilli(s string, _ *time.Location) (time.Time, error) {
+	if !isEpoch(s) {
+		return time.Time{}, errInvalidTimestamp
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return time.UnixMilli(n).UTC(), err
+}
+
+// parseLocal parses a wall clock in the requested timezone. A wall clock in
+// the gap of a daylight saving time transition is moved forward by it, and
+// an ambiguous one is the first.
+func parseLocal(s string, tz *time.Location) (time.Time, error) {
+	for _, layout := range localLayouts {
+		if c, err := time.Parse(layout, s); err == nil {
+			return period.Locate(c, tz).UTC(), nil
+		}
+	}
+	return time.Time{}, errInvalidTimestamp
+}
+
+// isEpoch reports whether s is an integer, with an optional minus sign.
+func isEpoch(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// basicLayout is the period.SUPPORTEDFORMAT with the offset of a zone other
+// than UTC, e.g. 20210729T090000+0300
+const basicLayout = "20060102T150405Z0700"
+
+// outputFormats are the formats of the response timestamps by their out
+// name. The epochs are JSON numbers.
+var outputFormats = map[string]func(t time.Time) interface{}{
+	"basic":   func(t time.Time) interface{} { return t.Format(basicLayout) },
+	"rfc3339": func(t time.Time) interface{} { return t.Format(time.RFC3339Nano) },
+	"epoch":   func(t time.Time) interface{} { return t.Unix() },
+	"epochms": func(t time.Time) interface{} { return t.UnixMilli() },
+}
+
+// output formats the timestamps of a response. The zero output formats them
+// in UTC and in the period.SUPPORTEDFORMAT.
+type output struct {
+	format func(t time.Time) interface{}
+	// loc is the zone of the timestamps, UTC when nil
+	loc *time.Location
+	// media is the media type of the streamed timestamps, JSON when empty
+	media string
+	// envelope is the metadata of the v2 media type
+	envelope *envelope
+}
+
+// parseOutput returns the output of the out and zone parameters, where the
+// local zone is the requested timezone tz.
+func parseOutput(out, zone string, tz *time.Location) (output, error) {
+	var o output
+	if out != "" {
+		f, ok := outputFormats[out]
+		if !ok {
+			return o, fmt.Errorf("%s is unknown output format. It should be one of "+
+				"basic, rfc3339, epoch or epochms", out)
+		}
+		o.format = f
+	}
+
+	switch zone {
+	case "", "utc":
+	case "local":
+		o.loc = tz
+	default:
+		return o, fmt.Errorf("%s is unknown output zone. It should be utc or local", zone)
+	}
+	return o, nil
+}
+
+// timestamp returns the formatted timestamp t.
+func (o output) timestamp(t time.Time) interface{} {
+	loc := o.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	if o.format == nil {
+		return t.In(loc).Format(basicLayout)
+	}
+	return o.format(t.In(loc))
+}
+
+// timestamps returns the formatted timestamps of a response, which is a JSON
+// array even without timestamps.
+func (o output) timestamps(list []time.Time) []interface{} {
+	ptlist := make([]interface{}, 0, len(list))
+	for _, t := range list {
+		ptlist = append(ptlist, o.timestamp(t))
+	}
+	return ptlist
+}
@@ -0,0 +1,127 @@
+package periodictask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampFormat(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	ts := time.Date(2021, 7, 29, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		tf       string
+		s        string
+		expected time.Time
+	}{
+		{"Basic", "basic", "20210729T060000Z", ts},
+		{"RFC 3339", "rfc3339", "2021-07-29T06:00:00Z", ts},
+		{"RFC 3339 with an offset", "rfc3339", "2021-07-29T09:00:00+03:00", ts},
+		{"RFC 3339 with a negative offset", "rfc3339", "2021-07-29T01:00:00-05:00", ts},
+		{"RFC 3339 with an unescaped +", "rfc3339", "2021-07-29T09:00:00 03:00", ts},
+		{"RFC 3339 with fractions", "rfc3339", "2021-07-29T09:00:00.250+03:00",
+			ts.Add(250 * time.Millisecond)},
+		{"Epoch", "epoch", "1627538400", ts},
+		{"Epoch in milliseconds", "epochms", "1627538400000", ts},
+		{"Local", "local", "2021-07-29T09:00:00", ts},
+		{"Local without seconds", "local", "2021-07-29 09:00", ts},
+		{"Local in the basic form", "local", "20210729T090000", ts},
+		{"Auto basic", "", "20210729T060000Z", ts},
+		{"Auto RFC 3339", "", "2021-07-29T09:00:00+03:00", ts},
+		{"Auto epoch", "", "1627538400", ts},
+		{"Auto epoch in milliseconds", "auto", "1627538400000", ts},
+		{"Auto local", "auto", "2021-07-29T09:00", ts},
+		// The clock moves from 03:00 to 04:00, and from 04:00 back to 03:00
+		{"Nonexistent local time", "local", "2021-03-28T03:30",
+			time.Date(2021, 3, 28, 1, 30, 0, 0, time.UTC)},
+		{"Ambiguous local time", "local", "2021-10-31T03:30",
+			time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC)},
+	}
+	h := &PeriodHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := h.timestampFormat(tt.tf)
+			assert.True(t, ok)
+
+			got, err := f(tt.s, tz)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestTimestampFormat_Errors(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	h := &PeriodHandler{}
+
+	for tf, list := range map[string][]string{
+		"basic":   {"2021-07-29T06:00:00Z", "20210729T060000"},
+		"rfc3339": {"20210729T060000Z", "2021-07-29T09:00:00"},
+		"epoch":   {"1627538400.5", "-", ""},
+		"epochms": {"2021-07-29"},
+		"local":   {"2021-07-29", "2021-07-29T09:00:00Z"},
+		"auto":    {"2021-07-29", "29072021T000000Z", "yesterday"},
+	} {
+		f, ok := h.timestampFormat(tf)
+		assert.True(t, ok, tf)
+		for _, s := range list {
+			_, err := f(s, tz)
+			assert.Error(t, err, "%s %s", tf, s)
+		}
+	}
+
+	_, ok := h.timestampFormat("iso")
+	assert.False(t, ok)
+}
+
+func TestTimestampFormat_Custom(t *testing.T) {
+	ts := time.Date(2021, 7, 29, 0, 0, 0, 0, time.UTC)
+	h := &PeriodHandler{Formats: map[string]TimestampFormat{
+		"date": func(s string, tz *time.Location) (time.Time, error) {
+			t, err := time.ParseInLocation("02/01/2006", s, tz)
+			return t.UTC(), err
+		},
+	}}
+
+	f, ok := h.timestampFormat("date")
+	if assert.True(t, ok) {
+		got, err := f("29/07/2021", time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, ts, got)
+	}
+
+	// The built-in formats are still there
+	_, ok = h.timestampFormat("epoch")
+	assert.True(t, ok)
+}
+
+func TestOutput(t *testing.T) {
+	tz, _ := time.LoadLocation("Europe/Athens")
+	ts := time.Date(2021, 7, 29, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		out, zone string
+		expected  interface{}
+	}{
+		{"", "", "20210729T060000Z"},
+		{"basic", "utc", "20210729T060000Z"},
+		{"basic", "local", "20210729T090000+0300"},
+		{"rfc3339", "", "2021-07-29T06:00:00Z"},
+		{"rfc3339", "local", "2021-07-29T09:00:00+03:00"},
+		{"epoch", "", int64(1627538400)},
+		{"epochms", "local", int64(1627538400000)},
+	}
+	for _, tt := range tests {
+		o, err := parseOutput(tt.out, tt.zone, tz)
+		assert.NoError(t, err, "%s %s", tt.out, tt.zone)
+		assert.Equal(t, tt.expected, o.timestamp(ts), "%s %s", tt.out, tt.zone)
+	}
+
+	_, err := parseOutput("iso", "", tz)
+	assert.Error(t, err)
+	_, err = parseOutput("", "Europe/Athens", tz)
+	assert.Error(t, err)
+}